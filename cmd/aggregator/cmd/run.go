@@ -23,6 +23,8 @@ import (
 	"github.com/shipa988/banner_rotator/cmd/aggregator/internal/app"
 )
 
+var upDB bool
+
 // runCmd represents the run command.
 var runCmd = &cobra.Command{
 	Use:   "run",
@@ -35,7 +37,7 @@ This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		a := app.NewApp()
-		if err := a.Run(cfg, debug); err != nil {
+		if err := a.Run(cfg, debug, upDB); err != nil {
 			log.Fatal(err)
 		}
 	},
@@ -49,6 +51,7 @@ func init() {
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	// runCmd.PersistentFlags().String("foo", "", "A help for foo")
+	runCmd.PersistentFlags().BoolVar(&upDB, "updb", false, "set if you want apply db migrations first")
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"sync"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
@@ -18,6 +19,12 @@ import (
 )
 
 const ErrAppRun = "can't run app"
+const ErrUpDB = "can't up db"
+
+const defaultCleanInterval = time.Hour
+
+// memoryDialect is the rotator in-memory db, it isn't shared with the aggregator.
+const memoryDialect = "memory"
 
 type App struct {
 }
@@ -25,11 +32,11 @@ type App struct {
 func NewApp() *App {
 	return &App{}
 }
-func (a *App) Run(cfg *Config, isDebug bool) (err error) {
+func (a *App) Run(cfg *Config, isDebug, upDB bool) (err error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	done := make(chan os.Signal)
+	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt)
 
 	logger, err := initLogger(cfg, isDebug)
@@ -41,12 +48,18 @@ func (a *App) Run(cfg *Config, isDebug bool) (err error) {
 	if err != nil {
 		return errors.Wrapf(err, ErrAppRun)
 	}
+	if upDB {
+		// migrations wait for the migration lock if the rotator is migrating the db.
+		if _, err := repo.MigrateUp(ctx); err != nil {
+			return errors.Wrapf(err, ErrUpDB)
+		}
+	}
 
 	broker, err := initQueueBroker(cfg)
 	if err != nil {
 		return errors.Wrapf(err, "can't queue manager")
 	}
-	aggregator, err := usecase.NewAggregatorInteractor(repo, broker, logger)
+	aggregator, err := usecase.NewAggregatorInteractor(repo, repo, repo, broker, cfg.Batch.Size, cfg.Batch.Interval, logger)
 
 	if err != nil {
 		logger.Log(ctx, err.Error())
@@ -62,6 +75,14 @@ func (a *App) Run(cfg *Config, isDebug bool) (err error) {
 		}
 	}()
 
+	if cfg.Stats.Retention > 0 {
+		cleanInterval := cfg.Stats.CleanInterval
+		if cleanInterval <= 0 {
+			cleanInterval = defaultCleanInterval
+		}
+		go aggregator.CleanHistory(ctx, cfg.Stats.Retention, cleanInterval)
+	}
+
 	go func() {
 		wg.Wait()
 		done <- os.Interrupt
@@ -105,6 +126,9 @@ func initLogger(cfg *Config, isDebug bool) (logger logger.Logger, err error) {
 }
 
 func initDB(cfg *Config) (*gorm.DB, error) {
+	if cfg.DB.Dialect == memoryDialect {
+		return nil, errors.New("memory dialect keeps data in the rotator process which writes events itself, the aggregator needs postgres or sqlite3")
+	}
 	db, err := gorm.Open(cfg.DB.Dialect, cfg.DB.DSN)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't initialize db")
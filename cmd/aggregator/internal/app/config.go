@@ -1,17 +1,22 @@
 package app
 
+import "time"
+
 type Config struct {
 	Log   Log   `yaml:"log"`
 	DB    DB    `yaml:"db"`
 	Queue Queue `yaml:"queue"`
 	Kafka Kafka `yaml:"kafka"`
+	Stats Stats `yaml:"stats"`
+	Batch Batch `yaml:"batch"`
 }
 type Log struct {
 	File string `yaml:"file"`
 }
 
 type DB struct {
-	DSN     string `yaml:"dsn"`
+	DSN string `yaml:"dsn"`
+	// Dialect is "postgres" or "sqlite3" with the db file path as dsn.
 	Dialect string `yaml:"dialect"`
 }
 
@@ -25,3 +30,16 @@ type Kafka struct {
 	MinSize       int    `yaml:"minsize"`
 	MaxSize       int    `yaml:"maxsize"`
 }
+
+type Stats struct {
+	// Retention is the age of kept statistics buckets, zero keeps buckets forever.
+	Retention     time.Duration `yaml:"retention"`
+	CleanInterval time.Duration `yaml:"cleaninterval"`
+}
+
+type Batch struct {
+	// Size is the max number of events written in one transaction.
+	Size int `yaml:"size"`
+	// Interval is the max time events wait to be written.
+	Interval time.Duration `yaml:"interval"`
+}
@@ -2,8 +2,11 @@ package usecase
 
 import (
 	"context"
+	"time"
 )
 
 type Aggregator interface {
 	ListenEvents(ctx context.Context) error
+	// CleanHistory deletes statistics buckets older than retention every cleanInterval until ctx is done.
+	CleanHistory(ctx context.Context, retention, cleanInterval time.Duration)
 }
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -11,58 +12,169 @@ import (
 )
 
 const (
-	ErrProcessClickEvent = `can't register click event for banner id: "%v", page: "%v", slot id: "%v"`
-	ErrProcessShowEvent  = `can't register show event for banner id: "%v", page: "%v", slot id: "%v"`
+	ErrProcessEvent  = `can't register %v event for banner id: "%v", page: "%v", slot id: "%v"`
+	ErrFindGroup     = `can't find group for user age: "%v", sex: "%v"`
+	ErrProcessBatch  = `can't register batch of %v events`
+	ErrCommitBatch   = `can't commit batch of %v events to queue`
+	ErrSkipIncrement = `skip %v events of deleted banner id: "%v", page: "%v", slot id: "%v" or group: "%v"`
+	ErrCleanHistory  = `can't delete statistics buckets before: "%v"`
+)
+
+const (
+	DefaultBatchSize     = 1000
+	DefaultBatchInterval = time.Second
 )
 
 var _ Aggregator = (*AggregatorInteractor)(nil)
 
 type AggregatorInteractor struct {
-	actionRepo entities.ActionRepository
-	queue      entities.EventQueue
-	logger     logger.Logger
+	batchRepo     entities.ActionBatchRepository
+	groupRepo     entities.GroupRepository
+	historyRepo   entities.HistoryRepository
+	queue         entities.EventQueue
+	batchSize     int
+	batchInterval time.Duration
+	logger        logger.Logger
 }
 
-func NewAggregatorInteractor(repo entities.ActionRepository, queueBroker entities.EventQueue, logger logger.Logger) (*AggregatorInteractor, error) {
+// NewAggregatorInteractor returns the aggregator which writes events by batches of batchSize events
+// or events received during batchInterval, zero values mean defaults.
+func NewAggregatorInteractor(batchRepo entities.ActionBatchRepository, groupRepo entities.GroupRepository, historyRepo entities.HistoryRepository, queueBroker entities.EventQueue, batchSize int, batchInterval time.Duration, logger logger.Logger) (*AggregatorInteractor, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if batchInterval <= 0 {
+		batchInterval = DefaultBatchInterval
+	}
 	return &AggregatorInteractor{
-		actionRepo: repo,
-		queue:      queueBroker,
-		logger:     logger,
+		batchRepo:     batchRepo,
+		groupRepo:     groupRepo,
+		historyRepo:   historyRepo,
+		queue:         queueBroker,
+		batchSize:     batchSize,
+		batchInterval: batchInterval,
+		logger:        logger,
 	}, nil
 }
 
-func (a *AggregatorInteractor) processEvent(ctx context.Context, wg *sync.WaitGroup, events <-chan entities.Event) {
+// groupDescription returns the group resolved by the rotator or finds it by user age and sex for events without group.
+func (a *AggregatorInteractor) groupDescription(ctx context.Context, event entities.Event) (string, error) {
+	if event.GroupDescription != "" {
+		return event.GroupDescription, nil
+	}
+	group, err := a.groupRepo.GetGroup(ctx, event.UserAge, event.UserSex)
+	if err != nil {
+		return "", err
+	}
+	return group.Description, nil
+}
+
+// addEvent adds the event to the batch and pulls it, events which can't be added are pulled and skipped.
+// It returns false if the group of the event isn't found because of a temporary error, so the event is retried.
+func (a *AggregatorInteractor) addEvent(ctx context.Context, batch *eventBatch, event entities.QueuedEvent) bool {
+	groupDescription, err := a.groupDescription(ctx, event.Event)
+	if err != nil {
+		a.logger.Log(ctx, errors.Wrapf(err, ErrFindGroup, event.UserAge, event.UserSex))
+		if !errors.Is(err, entities.ErrNotFound) && !errors.Is(err, entities.ErrInvalidArgument) {
+			return false
+		}
+	} else if err := batch.add(event.Event, groupDescription); err != nil {
+		a.logger.Log(ctx, errors.Wrapf(err, ErrProcessEvent, event.EventType, event.BannerID, event.PageURL, event.SlotID))
+	}
+	batch.pull(event)
+	return true
+}
+
+func (a *AggregatorInteractor) processEvent(ctx context.Context, wg *sync.WaitGroup, events <-chan entities.QueuedEvent) {
 	defer wg.Done()
+	ticker := time.NewTicker(a.batchInterval)
+	defer ticker.Stop()
+	batch := newEventBatch()
+	// retry is the event which group wasn't found because of a temporary error.
+	var retry *entities.QueuedEvent
 	loop := true
 	for loop {
+		in := events
+		if batch.size >= a.batchSize || retry != nil {
+			// the full batch wasn't written or the group of the event wasn't found,
+			// events wait in the queue until the ticker retries it.
+			in = nil
+		}
 		select {
-		case event := <-events:
-			switch event.EventType {
-			case "click":
-				if err := a.actionRepo.AddClickAction(event.PageURL, event.SlotID, event.BannerID, event.UserAge, event.UserSex); err != nil {
-					a.logger.Log(ctx, errors.Wrapf(err, ErrProcessClickEvent, event.BannerID, event.PageURL, event.SlotID))
-				}
-			case "show":
-				if err := a.actionRepo.AddShowAction(event.PageURL, event.SlotID, event.BannerID, event.UserAge, event.UserSex); err != nil {
-					a.logger.Log(ctx, errors.Wrapf(err, ErrProcessShowEvent, event.BannerID, event.PageURL, event.SlotID))
-				}
+		case event := <-in:
+			if !a.addEvent(ctx, batch, event) {
+				retry = &event
+				continue
+			}
+			if batch.size >= a.batchSize {
+				a.flush(ctx, batch)
+			}
+		case <-ticker.C:
+			if retry != nil && a.addEvent(ctx, batch, *retry) {
+				retry = nil
 			}
+			a.flush(ctx, batch)
 		case <-ctx.Done():
+			// the last batch is written after events listening is canceled,
+			// if it fails the events are pulled again after restart.
+			a.flush(context.Background(), batch)
 			loop = false
-			break
 		}
 	}
 }
 
+// flush writes the batch in one transaction, commits its events to the queue and resets it.
+// The batch is kept to retry on the next flush if it isn't written.
+func (a *AggregatorInteractor) flush(ctx context.Context, batch *eventBatch) {
+	if len(batch.last) == 0 {
+		return
+	}
+	if batch.size > 0 {
+		skipped, err := a.batchRepo.AddActions(ctx, batch.increments())
+		if err != nil {
+			a.logger.Log(ctx, errors.Wrapf(err, ErrProcessBatch, batch.size))
+			return
+		}
+		for _, inc := range skipped {
+			a.logger.Log(ctx, errors.Errorf(ErrSkipIncrement, inc.Clicks+inc.Shows+inc.Views, inc.BannerID, inc.PageURL, inc.SlotID, inc.GroupDescription))
+		}
+	}
+	// commits are cumulative, so events of a failed commit are committed with the next batch.
+	if err := a.queue.Commit(ctx, batch.pulled()); err != nil {
+		a.logger.Log(ctx, errors.Wrapf(err, ErrCommitBatch, batch.size))
+	}
+	batch.reset()
+}
+
 func (a *AggregatorInteractor) ListenEvents(ctx context.Context) error {
-	events := make(chan entities.Event)
+	events := make(chan entities.QueuedEvent)
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	go a.processEvent(ctx, wg, events)
-	if err := a.queue.Pull(ctx, events); err != nil {
-		close(events)
+	err := a.queue.Pull(ctx, events)
+	// the batch is written before returning.
+	cancel()
+	wg.Wait()
+	if err != nil {
 		return errors.Wrapf(err, "could't fetch messages from queue")
 	}
-	wg.Wait()
 	return nil
 }
+
+func (a *AggregatorInteractor) CleanHistory(ctx context.Context, retention, cleanInterval time.Duration) {
+	ticker := time.NewTicker(cleanInterval)
+	defer ticker.Stop()
+	for {
+		before := time.Now().Add(-retention)
+		if err := a.historyRepo.DeleteBucketsBefore(ctx, before); err != nil {
+			a.logger.Log(ctx, errors.Wrapf(err, ErrCleanHistory, before))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
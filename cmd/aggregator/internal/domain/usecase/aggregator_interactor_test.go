@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
+	"github.com/shipa988/banner_rotator/internal/data/repository"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+type testQueue struct {
+	mu      sync.Mutex
+	events  []entities.QueuedEvent
+	commits [][]entities.QueuedEvent
+}
+
+func (q *testQueue) Pull(ctx context.Context, events chan<- entities.QueuedEvent) error {
+	for _, event := range q.events {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (q *testQueue) Commit(ctx context.Context, events []entities.QueuedEvent) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.commits = append(q.commits, events)
+	return nil
+}
+
+func (q *testQueue) Push(ctx context.Context, event entities.Event) error {
+	return nil
+}
+
+func (q *testQueue) committed() [][]entities.QueuedEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([][]entities.QueuedEvent(nil), q.commits...)
+}
+
+// testBatchRepo fails the first fails calls of AddActions.
+type testBatchRepo struct {
+	mu      sync.Mutex
+	fails   int
+	batches [][]entities.ActionIncrement
+}
+
+func (r *testBatchRepo) AddActions(ctx context.Context, increments []entities.ActionIncrement) ([]entities.ActionIncrement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, increments)
+	if len(r.batches) <= r.fails {
+		return nil, errors.New("connection refused")
+	}
+	return nil, nil
+}
+
+func (r *testBatchRepo) written() [][]entities.ActionIncrement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]entities.ActionIncrement(nil), r.batches...)
+}
+
+func TestAggregatorInteractor_RetryBatch(t *testing.T) {
+	dt := time.Date(2020, 7, 1, 10, 30, 0, 0, time.UTC)
+	event := entities.Event{EventType: "show", DT: dt, PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man"}
+	queue := &testQueue{}
+	for offset := int64(0); offset < 3; offset++ {
+		queue.events = append(queue.events, entities.QueuedEvent{Event: event, Offset: offset})
+	}
+	repo := &testBatchRepo{fails: 1}
+	aggregator, err := NewAggregatorInteractor(repo, nil, nil, queue, 2, 10*time.Millisecond, zaplogger.NewLogger(ioutil.Discard, false))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- aggregator.ListenEvents(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		return len(queue.committed()) == 1
+	}, time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	batch := []entities.ActionIncrement{{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man", Hour: dt.Truncate(time.Hour), Action: entities.Action{Shows: 2}}}
+	written := repo.written()
+	require.Len(t, written, 3)
+	// the failed batch is retried without the events pulled after it.
+	require.Equal(t, batch, written[0])
+	require.Equal(t, batch, written[1])
+	batch[0].Shows = 1
+	require.Equal(t, batch, written[2])
+	require.Equal(t, [][]entities.QueuedEvent{{queue.events[1]}, {queue.events[2]}}, queue.committed())
+}
+
+// testGroupRepo fails the first fails calls of GetGroup and finds only old men.
+type testGroupRepo struct {
+	entities.GroupRepository
+	mu    sync.Mutex
+	fails int
+	calls int
+}
+
+func (r *testGroupRepo) GetGroup(ctx context.Context, userAge uint, userSex string) (*entities.Group, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.calls <= r.fails {
+		return nil, errors.New("connection refused")
+	}
+	if userAge <= 60 || userSex != "man" {
+		return nil, entities.ErrGroupForUserNotFound(userAge, userSex)
+	}
+	return &entities.Group{Description: "old man", Sex: "man", MinAge: 60, MaxAge: 150}, nil
+}
+
+func TestAggregatorInteractor_RetryGroup(t *testing.T) {
+	dt := time.Date(2020, 7, 1, 10, 30, 0, 0, time.UTC)
+	queue := &testQueue{events: []entities.QueuedEvent{
+		{Event: entities.Event{EventType: "show", DT: dt, PageURL: "site.com", SlotID: 1, BannerID: 1, UserAge: 70, UserSex: "man"}, Offset: 0},
+		{Event: entities.Event{EventType: "show", DT: dt, PageURL: "site.com", SlotID: 1, BannerID: 1, UserAge: 5, UserSex: "man"}, Offset: 1},
+	}}
+	repo := &testBatchRepo{}
+	aggregator, err := NewAggregatorInteractor(repo, &testGroupRepo{fails: 2}, nil, queue, 10, 10*time.Millisecond, zaplogger.NewLogger(ioutil.Discard, false))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- aggregator.ListenEvents(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		return len(queue.committed()) == 2
+	}, time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	// the event is kept until its group is found, the event of the user without group is skipped.
+	batch := []entities.ActionIncrement{{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man", Hour: dt.Truncate(time.Hour), Action: entities.Action{Shows: 1}}}
+	require.Equal(t, [][]entities.ActionIncrement{batch}, repo.written())
+	require.Equal(t, [][]entities.QueuedEvent{{queue.events[0]}, {queue.events[1]}}, queue.committed())
+}
+
+func TestAggregatorInteractor_CleanHistory(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemRepo(zaplogger.NewLogger(ioutil.Discard, false))
+	require.NoError(t, repo.AddGroup(ctx, entities.Group{Description: "old man", Sex: "man", MinAge: 61, MaxAge: 150}, nil))
+	require.NoError(t, repo.AddSlot(ctx, "site.com", 1, "top", false, 0, 0))
+	require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 1, 1, "sale", entities.Creative{}))
+	now := time.Now().UTC().Truncate(time.Hour)
+	_, err := repo.AddActions(ctx, []entities.ActionIncrement{
+		{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man", Hour: now.Add(-48 * time.Hour), Action: entities.Action{Shows: 1}},
+		{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man", Hour: now, Action: entities.Action{Shows: 1}},
+	})
+	require.NoError(t, err)
+	aggregator, err := NewAggregatorInteractor(repo, repo, repo, &testQueue{}, 0, 0, zaplogger.NewLogger(ioutil.Discard, false))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		aggregator.CleanHistory(ctx, 24*time.Hour, time.Hour)
+		close(done)
+	}()
+	// buckets older than retention are deleted on start.
+	require.Eventually(t, func() bool {
+		buckets, err := repo.GetBuckets(context.Background(), "site.com", 0, 0, now.Add(-72*time.Hour), now.Add(time.Hour))
+		return err == nil && len(buckets) == 1 && buckets[0].Start.Equal(now)
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-done
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+type incrementKey struct {
+	pageURL          string
+	slotID           uint
+	bannerID         uint
+	groupDescription string
+	hour             int64
+}
+
+// eventBatch collapses events into increments of the banner actions by user group and hour.
+type eventBatch struct {
+	size  int
+	order []incrementKey
+	sums  map[incrementKey]*entities.ActionIncrement
+	// last keeps the last pulled event of every queue partition to commit the batch.
+	last map[int]entities.QueuedEvent
+}
+
+func newEventBatch() *eventBatch {
+	b := &eventBatch{}
+	b.reset()
+	return b
+}
+
+func (b *eventBatch) add(event entities.Event, groupDescription string) error {
+	hour := event.DT.UTC().Truncate(time.Hour)
+	key := incrementKey{
+		pageURL:          event.PageURL,
+		slotID:           event.SlotID,
+		bannerID:         event.BannerID,
+		groupDescription: groupDescription,
+		hour:             hour.Unix(),
+	}
+	inc, ok := b.sums[key]
+	if !ok {
+		inc = &entities.ActionIncrement{
+			PageURL:          event.PageURL,
+			SlotID:           event.SlotID,
+			BannerID:         event.BannerID,
+			GroupDescription: groupDescription,
+			Hour:             hour,
+		}
+	}
+	if err := inc.Add(event.EventType); err != nil {
+		return err
+	}
+	if !ok {
+		b.sums[key] = inc
+		b.order = append(b.order, key)
+	}
+	b.size++
+	return nil
+}
+
+// pull remembers the queue position of the event, including events that aren't added.
+func (b *eventBatch) pull(event entities.QueuedEvent) {
+	b.last[event.Partition] = event
+}
+
+// pulled returns the last pulled events of partitions.
+func (b *eventBatch) pulled() []entities.QueuedEvent {
+	events := make([]entities.QueuedEvent, 0, len(b.last))
+	for _, event := range b.last {
+		events = append(events, event)
+	}
+	return events
+}
+
+// increments returns increments in order of their first events.
+func (b *eventBatch) increments() []entities.ActionIncrement {
+	increments := make([]entities.ActionIncrement, 0, len(b.order))
+	for _, key := range b.order {
+		increments = append(increments, *b.sums[key])
+	}
+	return increments
+}
+
+func (b *eventBatch) reset() {
+	b.size = 0
+	b.order = nil
+	b.sums = make(map[incrementKey]*entities.ActionIncrement)
+	b.last = make(map[int]entities.QueuedEvent)
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func TestEventBatch(t *testing.T) {
+	dt := time.Date(2020, 7, 1, 10, 30, 0, 0, time.UTC)
+	batch := newEventBatch()
+	for _, event := range []entities.Event{
+		{EventType: "show", DT: dt, PageURL: "site.com", SlotID: 1, BannerID: 1},
+		{EventType: "click", DT: dt.Add(time.Minute), PageURL: "site.com", SlotID: 1, BannerID: 1},
+		{EventType: "show", DT: dt.Add(time.Hour), PageURL: "site.com", SlotID: 1, BannerID: 1},
+		{EventType: "view", DT: dt, PageURL: "site.com", SlotID: 1, BannerID: 2},
+	} {
+		require.NoError(t, batch.add(event, "old man"))
+	}
+	require.Error(t, batch.add(entities.Event{EventType: "hover", DT: dt}, "old man"))
+	require.Equal(t, 4, batch.size)
+
+	hour := dt.Truncate(time.Hour)
+	require.Equal(t, []entities.ActionIncrement{
+		{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man", Hour: hour, Action: entities.Action{Clicks: 1, Shows: 1}},
+		{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man", Hour: hour.Add(time.Hour), Action: entities.Action{Shows: 1}},
+		{PageURL: "site.com", SlotID: 1, BannerID: 2, GroupDescription: "old man", Hour: hour, Action: entities.Action{Views: 1}},
+	}, batch.increments())
+
+	batch.reset()
+	require.Empty(t, batch.increments())
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/data/app"
+)
+
+var applyFile string
+var applyPrune bool
+var applyDryRun bool
+
+// applyCmd represents the apply command.
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Sync pages, slots and banners with YAML or JSON inventory file",
+	Long: `Sync pages, slots and banners with YAML or JSON inventory file.
+The plan of changes between the inventory and the db is printed and then applied.
+Pages, slots and banners which are absent in the inventory are deleted only with --prune,
+their events are deleted too.
+
+rotator apply -f inventory.yaml --prune --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		a := app.NewApp()
+		if err := a.Apply(cfg, debug, os.Stdout, applyFile, applyPrune, applyDryRun); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "", "YAML or JSON inventory file")
+	applyCmd.Flags().BoolVar(&applyPrune, "prune", false, "delete pages, slots and banners which are absent in the inventory")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "print the plan without applying it")
+	if err := applyCmd.MarkFlagRequired("file"); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/data/app"
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/data/export"
+)
+
+var exportFormat string
+var exportOut string
+var exportPageURL string
+var exportFrom string
+var exportTo string
+
+// exportStatsCmd represents the export-stats command.
+var exportStatsCmd = &cobra.Command{
+	Use:   "export-stats",
+	Short: "Export banner stats to CSV or Parquet file",
+	Long: `Export stats by page, slot, banner and user group with shows, clicks, views and CTR.
+Lifetime stats are exported when time range is not set, otherwise stats are taken from hourly buckets.
+
+rotator export-stats --format parquet --out stats.parquet --from 2020-07-01T00:00:00Z`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// the file is closed before exit.
+		if err := exportStats(); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func exportStats() (err error) {
+	format, err := export.ParseFormat(exportFormat)
+	if err != nil {
+		return err
+	}
+	from, err := parseTimeFlag(exportFrom)
+	if err != nil {
+		return err
+	}
+	to, err := parseTimeFlag(exportTo)
+	if err != nil {
+		return err
+	}
+	out := os.Stdout
+	if exportOut != "" {
+		out, err = os.Create(exportOut)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+		}()
+	}
+	a := app.NewApp()
+	return a.ExportStats(cfg, debug, out, format, exportPageURL, from, to)
+}
+
+func parseTimeFlag(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func init() {
+	rootCmd.AddCommand(exportStatsCmd)
+
+	exportStatsCmd.Flags().StringVar(&exportFormat, "format", "csv", "file format: csv or parquet")
+	exportStatsCmd.Flags().StringVar(&exportOut, "out", "", "output file (default is stdout)")
+	exportStatsCmd.Flags().StringVar(&exportPageURL, "page", "", "page url (default is all pages)")
+	exportStatsCmd.Flags().StringVar(&exportFrom, "from", "", "start of time range in RFC3339")
+	exportStatsCmd.Flags().StringVar(&exportTo, "to", "", "end of time range in RFC3339 (default is now)")
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/data/app"
+)
+
+var migrateSteps int
+
+// migrateCmd represents the migrate command.
+var migrateCmd = &cobra.Command{
+	Use:   "migrate up|down|status",
+	Short: "Apply, revert or list versioned db schema migrations",
+	Long: `Apply, revert or list versioned db schema migrations.
+
+up applies all not applied migrations, down reverts the --steps last applied
+migrations, status lists migrations with the time they were applied.
+Migrations run in one transaction under the migration lock, so the rotator
+and the aggregator never migrate the db concurrently. For example:
+
+rotator migrate up
+rotator migrate down --steps 2
+rotator migrate status`,
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: []string{app.MigrateUp, app.MigrateDown, app.MigrateStatus},
+	Run: func(cmd *cobra.Command, args []string) {
+		a := app.NewApp()
+		if err := a.Migrate(cfg, debug, os.Stdout, args[0], migrateSteps); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().IntVar(&migrateSteps, "steps", 1, "number of migrations to revert by down")
+}
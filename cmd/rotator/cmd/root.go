@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -63,6 +64,11 @@ func initConfig() {
 		log.Fatal(err)
 	}
 
+	// seed fixture path is relative to the config file.
+	if cfg.DB.Seed != "" && !filepath.IsAbs(cfg.DB.Seed) {
+		cfg.DB.Seed = filepath.Join(filepath.Dir(viper.ConfigFileUsed()), cfg.DB.Seed)
+	}
+
 	//how can i substitute config param in windows? evsubst is absent =(
 	if dsn, ok := viper.Get("DSN").(string); dsn != "" && ok {
 		cfg.DB.DSN = dsn
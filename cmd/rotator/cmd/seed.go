@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/data/app"
+)
+
+var seedFile string
+
+// seedCmd represents the seed command.
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Seed groups, pages, slots and banners from YAML or JSON fixture file",
+	Long: `Seed groups, pages, slots and banners from YAML or JSON fixture file.
+Missing entities are created, entities which differ from the fixture are updated,
+the others are skipped, so the same fixture can be seeded many times.
+
+rotator seed --file cmd/rotator/config/seed.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		a := app.NewApp()
+		if err := a.Seed(cfg, debug, os.Stdout, seedFile); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+
+	seedCmd.Flags().StringVar(&seedFile, "file", "", "YAML or JSON fixture file")
+	if err := seedCmd.MarkFlagRequired("file"); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,6 +1,7 @@
 package multiarms
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sync"
@@ -15,6 +16,12 @@ var algoErr = func(page string, slotId uint, groupDescription string) *usecase.A
 		IsOldSchema: true,
 	}
 }
+var noCandidatesErr = func(page string, slotId uint, groupDescription string) *usecase.AlgoError {
+	return &usecase.AlgoError{
+		Mess:        fmt.Sprintf("all banners for page: %v, slotId: %v, groupDescription: %v are filtered", page, slotId, groupDescription),
+		IsOldSchema: false,
+	}
+}
 
 type state struct {
 	arms    map[uint]*arm
@@ -34,7 +41,7 @@ type UCB1Algo struct {
 	states map[string]map[uint]map[groupName]*state
 }
 
-func (a *UCB1Algo) Init(pages *usecase.Pages) error {
+func (a *UCB1Algo) Init(ctx context.Context, pages *usecase.Pages) error {
 	a.Lock()
 	a.states = make(map[string]map[uint]map[groupName]*state)
 	pgs := a.states
@@ -56,10 +63,10 @@ func (a *UCB1Algo) Init(pages *usecase.Pages) error {
 						st = grps[groupName(group.Description)]
 					}
 					st.arms[banner.InnerID] = &arm{
-						try:    float64(action.Shows),
+						try:    float64(slot.Tries(action)),
 						reward: float64(action.Clicks),
 					}
-					st.trys += float64(action.Shows)
+					st.trys += float64(slot.Tries(action))
 					/*
 						a.states[page.URL][slot.InnerID][groupName(group.Description)] = &state*/
 				}
@@ -74,7 +81,7 @@ func (a *UCB1Algo) Init(pages *usecase.Pages) error {
 	return nil
 }
 
-func (a *UCB1Algo) UpdateTry(pageURL string, slotID, bannerID uint, groupDescription string) (err error) {
+func (a *UCB1Algo) UpdateTry(ctx context.Context, pageURL string, slotID, bannerID uint, groupDescription string) (err error) {
 	a.Lock()
 	defer a.Unlock()
 	s, ok := a.states[pageURL][slotID][groupName(groupDescription)]
@@ -93,7 +100,7 @@ func (a *UCB1Algo) UpdateTry(pageURL string, slotID, bannerID uint, groupDescrip
 	return nil
 }
 
-func (a *UCB1Algo) UpdateReward(pageURL string, slotID, bannerID uint, groupDescription string) (err error) {
+func (a *UCB1Algo) UpdateReward(ctx context.Context, pageURL string, slotID, bannerID uint, groupDescription string) (err error) {
 	a.Lock()
 	defer a.Unlock()
 	s, ok := a.states[pageURL][slotID][groupName(groupDescription)]
@@ -111,19 +118,54 @@ func (a *UCB1Algo) UpdateReward(pageURL string, slotID, bannerID uint, groupDesc
 	return nil
 }
 
+func (a *UCB1Algo) RemovePage(ctx context.Context, pageURL string) error {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.states, pageURL)
+	return nil
+}
+
+// Scores returns upper confidence bounds of arms, untried arms have infinite score.
+func (a *UCB1Algo) Scores(ctx context.Context, pageURL string, slotID uint) (scores map[string]map[uint]float64, err error) {
+	a.RLock()
+	defer a.RUnlock()
+	groups, ok := a.states[pageURL][slotID]
+	if !ok {
+		return nil, algoErr(pageURL, slotID, "")
+	}
+	scores = make(map[string]map[uint]float64, len(groups))
+	for group, s := range groups {
+		scores[string(group)] = make(map[uint]float64, len(s.arms))
+		for armID, armState := range s.arms {
+			if armState.try == 0 {
+				scores[string(group)][armID] = math.Inf(1)
+				continue
+			}
+			x := armState.reward / armState.try
+			scores[string(group)][armID] = x + math.Sqrt(2*math.Log(s.trys)/armState.try)
+		}
+	}
+	return scores, nil
+}
+
 func NewUCB1Algo() *UCB1Algo {
 	return &UCB1Algo{}
 }
 
-func (a *UCB1Algo) GetNext(pageURL string, slotID uint, groupDescription string) (id uint, err error) {
+func (a *UCB1Algo) GetNext(ctx context.Context, pageURL string, slotID uint, groupDescription string, filter usecase.ArmFilter) (id uint, err error) {
 	a.RLock()
 	defer a.RUnlock()
-	if state := a.states[pageURL][slotID][groupName(groupDescription)]; state != nil {
+	state := a.states[pageURL][slotID][groupName(groupDescription)]
+	if state == nil {
+		return 0, algoErr(pageURL, slotID, groupDescription)
+	}
+	if filter == nil {
 		return state.nextarm, nil
 	}
-	err = algoErr(pageURL, slotID, groupDescription)
-
-	return 0, err
+	if id, ok := a.bestArm(state, filter); ok {
+		return id, nil
+	}
+	return 0, noCandidatesErr(pageURL, slotID, groupDescription)
 }
 
 func (a *UCB1Algo) setNext(s *state) {
@@ -140,3 +182,23 @@ func (a *UCB1Algo) setNext(s *state) {
 		}
 	}
 }
+
+// bestArm returns the arm with max upper confidence bound among arms allowed by filter.
+func (a *UCB1Algo) bestArm(s *state, filter usecase.ArmFilter) (id uint, ok bool) {
+	max := math.Inf(-1)
+	for armID, armState := range s.arms {
+		if !filter(armID) {
+			continue
+		}
+		if armState.try == 0 {
+			return armID, true
+		}
+		x := armState.reward / armState.try
+		val := x + math.Sqrt(2*math.Log(s.trys)/armState.try)
+		if val > max {
+			max = val
+			id, ok = armID, true
+		}
+	}
+	return
+}
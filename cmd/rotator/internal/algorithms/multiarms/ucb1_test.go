@@ -2,6 +2,7 @@
 package multiarms
 
 import (
+	"context"
 	"math"
 	"testing"
 
@@ -18,11 +19,12 @@ const (
 )
 
 func TestNewUCB1Algo(t *testing.T) {
+	ctx := context.Background()
 	pages := initPages()
 
 	t.Run("Init", func(t *testing.T) {
 		algo := NewUCB1Algo()
-		err := algo.Init(pages)
+		err := algo.Init(ctx, pages)
 		require.Nil(t, err)
 		expStates := initStates()
 
@@ -31,20 +33,69 @@ func TestNewUCB1Algo(t *testing.T) {
 
 	t.Run("GetNext", func(t *testing.T) {
 		algo := NewUCB1Algo()
-		err := algo.Init(pages)
+		err := algo.Init(ctx, pages)
 		require.Nil(t, err)
 		expStates := initStates()
 
 		expNext := expStates[pageURL][slotID][groupDescription].nextarm
 
-		next, err := algo.GetNext(pageURL, slotID, groupDescription)
+		next, err := algo.GetNext(ctx, pageURL, slotID, groupDescription, nil)
 		require.Nil(t, err)
 		require.Equal(t, expNext, next)
 	})
 
+	t.Run("GetNext filtered", func(t *testing.T) {
+		algo := NewUCB1Algo()
+		err := algo.Init(ctx, pages)
+		require.Nil(t, err)
+		expStates := initStates()
+
+		best := expStates[pageURL][slotID][groupDescription].nextarm
+		next, err := algo.GetNext(ctx, pageURL, slotID, groupDescription, func(bannerID uint) bool {
+			return bannerID != best
+		})
+		require.Nil(t, err)
+		require.NotEqual(t, best, next)
+
+		_, err = algo.GetNext(ctx, pageURL, slotID, groupDescription, func(bannerID uint) bool {
+			return false
+		})
+		require.NotNil(t, err)
+		require.False(t, err.(*usecase.AlgoError).Temporary())
+	})
+
+	t.Run("RemovePage", func(t *testing.T) {
+		algo := NewUCB1Algo()
+		err := algo.Init(ctx, pages)
+		require.Nil(t, err)
+
+		require.Nil(t, algo.RemovePage(ctx, pageURL))
+		_, err = algo.GetNext(ctx, pageURL, slotID, groupDescription, nil)
+		e, ok := err.(*usecase.AlgoError)
+		require.True(t, ok)
+		require.True(t, e.Temporary())
+	})
+
+	t.Run("Scores", func(t *testing.T) {
+		algo := NewUCB1Algo()
+		err := algo.Init(ctx, pages)
+		require.Nil(t, err)
+		expStates := initStates()
+
+		scores, err := algo.Scores(ctx, pageURL, slotID)
+		require.Nil(t, err)
+		best := expStates[pageURL][slotID][groupDescription].nextarm
+		for bannerID, score := range scores[groupDescription] {
+			require.LessOrEqual(t, score, scores[groupDescription][best], "banner %v", bannerID)
+		}
+
+		_, err = algo.Scores(ctx, pageURL, slotID+1)
+		require.NotNil(t, err)
+	})
+
 	t.Run("UpdateTry", func(t *testing.T) {
 		algo := NewUCB1Algo()
-		err := algo.Init(pages)
+		err := algo.Init(ctx, pages)
 		require.Nil(t, err)
 		expStates := initStates()
 
@@ -55,7 +106,7 @@ func TestNewUCB1Algo(t *testing.T) {
 		expAllTryes := expStates[pageURL][slotID][groupDescription].trys
 		expNext := getMaxArm(expStates[pageURL][slotID][groupDescription].arms)
 
-		err = algo.UpdateTry(pageURL, slotID, 1, groupDescription)
+		err = algo.UpdateTry(ctx, pageURL, slotID, 1, groupDescription)
 
 		require.Nil(t, err)
 		require.Equal(t, expNext, algo.states[pageURL][slotID][groupDescription].nextarm)
@@ -65,7 +116,7 @@ func TestNewUCB1Algo(t *testing.T) {
 
 	t.Run("UpdateReward", func(t *testing.T) {
 		algo := NewUCB1Algo()
-		err := algo.Init(pages)
+		err := algo.Init(ctx, pages)
 		require.Nil(t, err)
 		expStates := initStates()
 
@@ -73,7 +124,7 @@ func TestNewUCB1Algo(t *testing.T) {
 		expArm1Reward := expStates[pageURL][slotID][groupDescription].arms[1].reward
 		expNext := getMaxArm(expStates[pageURL][slotID][groupDescription].arms)
 
-		err = algo.UpdateReward(pageURL, slotID, 1, groupDescription)
+		err = algo.UpdateReward(ctx, pageURL, slotID, 1, groupDescription)
 
 		require.Nil(t, err)
 		require.Equal(t, expNext, algo.states[pageURL][slotID][groupDescription].nextarm)
@@ -83,23 +134,23 @@ func TestNewUCB1Algo(t *testing.T) {
 
 	t.Run("When Clicking on Banner often-this banner shows often, but another banners also should be show", func(t *testing.T) {
 		algo := NewUCB1Algo()
-		err := algo.Init(pages)
+		err := algo.Init(ctx, pages)
 		require.Nil(t, err)
 		clicks := 60.0
 		var expNext uint = 1
 
 		//clicking on banner 1
 		for i := 0; i < int(clicks); i++ {
-			err := algo.UpdateReward(pageURL, slotID, expNext, groupDescription)
+			err := algo.UpdateReward(ctx, pageURL, slotID, expNext, groupDescription)
 			require.Nil(t, err)
 		}
 
 		//show banners 200 iterations
 		nexts := make(map[uint]int)
 		for i := 0; i < 200; i++ {
-			next, err := algo.GetNext(pageURL, slotID, groupDescription)
+			next, err := algo.GetNext(ctx, pageURL, slotID, groupDescription, nil)
 			require.Nil(t, err)
-			err = algo.UpdateTry(pageURL, slotID, next, groupDescription)
+			err = algo.UpdateTry(ctx, pageURL, slotID, next, groupDescription)
 			require.Nil(t, err)
 			nexts[next]++
 		}
@@ -1,6 +1,8 @@
 package random
 
 import (
+	"context"
+
 	"github.com/shipa988/banner_rotator/cmd/rotator/internal/domain/usecase"
 )
 
@@ -9,19 +11,27 @@ var _ usecase.NextBannerAlgo = (*Randomizer)(nil)
 type Randomizer struct {
 }
 
-func (r Randomizer) GetNext(pageURL string, slotID uint, groupDescription string) (id uint, err error) {
+func (r Randomizer) GetNext(ctx context.Context, pageURL string, slotID uint, groupDescription string, filter usecase.ArmFilter) (id uint, err error) {
+	panic("implement me")
+}
+
+func (r Randomizer) UpdateTry(ctx context.Context, pageURL string, slotID, bannerID uint, groupDescription string) error {
+	panic("implement me")
+}
+
+func (r Randomizer) UpdateReward(ctx context.Context, pageURL string, slotID, bannerID uint, groupDescription string) error {
 	panic("implement me")
 }
 
-func (r Randomizer) UpdateTry(pageURL string, slotID, bannerID uint, groupDescription string) error {
+func (r Randomizer) Init(ctx context.Context, pages *usecase.Pages) error {
 	panic("implement me")
 }
 
-func (r Randomizer) UpdateReward(pageURL string, slotID, bannerID uint, groupDescription string) error {
+func (r Randomizer) RemovePage(ctx context.Context, pageURL string) error {
 	panic("implement me")
 }
 
-func (r Randomizer) Init(pages *usecase.Pages) error {
+func (r Randomizer) Scores(ctx context.Context, pageURL string, slotID uint) (scores map[string]map[uint]float64, err error) {
 	panic("implement me")
 }
 
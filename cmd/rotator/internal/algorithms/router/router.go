@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/domain/usecase"
+)
+
+var _ usecase.NextBannerAlgo = (*PageRouter)(nil)
+var _ usecase.AlgoRegistry = (*PageRouter)(nil)
+
+// PageRouter rotates each page with the algorithm chosen in the page settings.
+type PageRouter struct {
+	sync.RWMutex
+	defaultName string
+	algos       map[string]usecase.NextBannerAlgo
+	// page url -> algorithm name.
+	pages map[string]string
+}
+
+func NewPageRouter(defaultName string, defaultAlgo usecase.NextBannerAlgo) *PageRouter {
+	return &PageRouter{
+		defaultName: defaultName,
+		algos:       map[string]usecase.NextBannerAlgo{defaultName: defaultAlgo},
+		pages:       make(map[string]string),
+	}
+}
+
+// Add registers the algorithm which can be chosen for pages by name.
+func (r *PageRouter) Add(name string, algo usecase.NextBannerAlgo) {
+	r.Lock()
+	defer r.Unlock()
+	r.algos[name] = algo
+}
+
+func (r *PageRouter) HasAlgorithm(name string) bool {
+	r.RLock()
+	defer r.RUnlock()
+	_, ok := r.algos[name]
+	return ok
+}
+
+func (r *PageRouter) Init(ctx context.Context, pages *usecase.Pages) error {
+	r.Lock()
+	defer r.Unlock()
+	parts := make(map[string]usecase.Pages, len(r.algos))
+	for name := range r.algos {
+		parts[name] = usecase.Pages{}
+	}
+	r.pages = make(map[string]string)
+	for page, slots := range *pages {
+		name := page.Algorithm
+		if _, ok := r.algos[name]; !ok {
+			name = r.defaultName
+		}
+		parts[name][page] = slots
+		r.pages[page.URL] = name
+	}
+	for name, algo := range r.algos {
+		part := parts[name]
+		if err := algo.Init(ctx, &part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PageRouter) GetNext(ctx context.Context, pageURL string, slotID uint, groupDescription string, filter usecase.ArmFilter) (id uint, err error) {
+	return r.algo(pageURL).GetNext(ctx, pageURL, slotID, groupDescription, filter)
+}
+
+func (r *PageRouter) UpdateTry(ctx context.Context, pageURL string, slotID, bannerID uint, groupDescription string) error {
+	return r.algo(pageURL).UpdateTry(ctx, pageURL, slotID, bannerID, groupDescription)
+}
+
+func (r *PageRouter) UpdateReward(ctx context.Context, pageURL string, slotID, bannerID uint, groupDescription string) error {
+	return r.algo(pageURL).UpdateReward(ctx, pageURL, slotID, bannerID, groupDescription)
+}
+
+func (r *PageRouter) RemovePage(ctx context.Context, pageURL string) error {
+	if err := r.algo(pageURL).RemovePage(ctx, pageURL); err != nil {
+		return err
+	}
+	r.Lock()
+	defer r.Unlock()
+	delete(r.pages, pageURL)
+	return nil
+}
+
+func (r *PageRouter) Scores(ctx context.Context, pageURL string, slotID uint) (scores map[string]map[uint]float64, err error) {
+	return r.algo(pageURL).Scores(ctx, pageURL, slotID)
+}
+
+// algo returns the algorithm of the page, unknown pages are rotated by the default algorithm.
+func (r *PageRouter) algo(pageURL string) usecase.NextBannerAlgo {
+	r.RLock()
+	defer r.RUnlock()
+	if name, ok := r.pages[pageURL]; ok {
+		return r.algos[name]
+	}
+	return r.algos[r.defaultName]
+}
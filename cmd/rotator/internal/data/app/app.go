@@ -3,19 +3,25 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/signal"
 	"sync"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 
 	"github.com/shipa988/banner_rotator/cmd/rotator/internal/algorithms/multiarms"
 	"github.com/shipa988/banner_rotator/cmd/rotator/internal/algorithms/random"
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/algorithms/router"
 	"github.com/shipa988/banner_rotator/cmd/rotator/internal/data/controllers/grpcservice"
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/data/export"
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/data/fixture"
 	"github.com/shipa988/banner_rotator/cmd/rotator/internal/domain/usecase"
 	"github.com/shipa988/banner_rotator/internal/data/controllers/queueservice/kafkaservice"
+	"github.com/shipa988/banner_rotator/internal/data/controllers/queueservice/repoqueue"
 	"github.com/shipa988/banner_rotator/internal/data/logger"
 	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
 	"github.com/shipa988/banner_rotator/internal/data/repository"
@@ -26,6 +32,20 @@ const ErrAppInit = "can't init app"
 const ErrAppRun = "can't run app"
 const ErrUpDB = "can't up db"
 const ErrDownDB = "can't down db"
+const ErrExportStats = "can't export stats"
+const ErrMigrate = "can't migrate db"
+const ErrSeed = "can't seed db"
+const ErrApply = "can't apply inventory"
+
+// Migration directions of Migrate.
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+)
+
+// memoryDialect keeps data in memory of the rotator process.
+const memoryDialect = "memory"
 
 type App struct {
 }
@@ -34,40 +54,64 @@ func NewApp() *App {
 	return &App{}
 }
 
-func (a *App) initRotator(cfg *Config, isDebug, upDB bool, logger logger.Logger) (rotator usecase.Rotator, err error) {
-	repo, err := initRepo(cfg, logger, isDebug)
-	if err != nil {
-		return nil, errors.Wrapf(err, ErrAppInit)
-	}
-
+func (a *App) initRotator(ctx context.Context, cfg *Config, isDebug, upDB bool, logger logger.Logger) (rotator usecase.Rotator, err error) {
 	if upDB {
 		if err := a.DBUp(cfg, isDebug); err != nil {
 			return nil, errors.Wrapf(err, ErrAppInit)
 		}
 	}
 
-	algo, err := initAlgo(cfg)
+	rotator, err = newRotator(cfg, isDebug, logger)
 	if err != nil {
 		return nil, errors.Wrapf(err, ErrAppInit)
 	}
-
-	broker, err := initQueueBroker(cfg)
-	if err != nil {
-		return nil, errors.Wrapf(err, ErrAppInit)
+	// in-memory db is empty on every start.
+	if cfg.DB.Seed != "" && (upDB || cfg.DB.Dialect == memoryDialect) {
+		report, err := seed(ctx, rotator, cfg.DB.Seed)
+		if err != nil {
+			return nil, errors.Wrapf(err, ErrAppInit)
+		}
+		logger.Log(context.Background(), "db seeded from %v: created %v, updated %v, skipped %v", cfg.DB.Seed, len(report.Created), len(report.Updated), len(report.Skipped))
 	}
-
-	rotator, err = usecase.NewRotatorInteractor(repo, broker, algo, logger)
+	err = rotator.Init(ctx)
 	if err != nil {
 		return nil, errors.Wrapf(err, ErrAppInit)
 	}
-	err = rotator.Init()
+	// events of users which match no group are skipped without the default group.
+	_, defaultGroupDescription, err := rotator.GetGroups(ctx)
 	if err != nil {
 		return nil, errors.Wrapf(err, ErrAppInit)
 	}
+	if defaultGroupDescription == "" {
+		logger.Log(ctx, "warning: there is no default group, events of users which match no group are not counted, seed the default group from db.seed")
+	}
 
 	return
 }
 
+// newRotator returns rotator without loaded algorithm state.
+func newRotator(cfg *Config, isDebug bool, logger logger.Logger) (rotator usecase.Rotator, err error) {
+	repo, err := initRepo(cfg, logger, isDebug)
+	if err != nil {
+		return nil, err
+	}
+
+	algo, err := initAlgo(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var broker entities.EventQueue
+	if memRepo, ok := repo.(*repository.MemRepo); ok {
+		// the aggregator can't reach the in-memory db, so events are written by the rotator.
+		broker = repoqueue.NewRepoQueue(memRepo)
+	} else if broker, err = initQueueBroker(cfg); err != nil {
+		return nil, err
+	}
+
+	return usecase.NewRotatorInteractor(repo, broker, algo, usecase.NewStatsBus(cfg.Stats.Interval), logger)
+}
+
 func (a *App) Run(cfg *Config, isDebug, upDB bool) (err error) {
 	fmt.Println(cfg)
 	logger, err := initLogger(cfg, isDebug)
@@ -75,7 +119,7 @@ func (a *App) Run(cfg *Config, isDebug, upDB bool) (err error) {
 		return errors.Wrapf(err, ErrAppRun)
 	}
 
-	rotator, err := a.initRotator(cfg, isDebug, upDB, logger)
+	rotator, err := a.initRotator(context.Background(), cfg, isDebug, upDB, logger)
 	if err != nil {
 		return errors.Wrapf(err, ErrAppRun)
 	}
@@ -115,6 +159,135 @@ func (a *App) Run(cfg *Config, isDebug, upDB bool) (err error) {
 	return nil
 }
 
+// ExportStats writes stats of the page by slot, banner and group to w, empty page url means all pages.
+func (a *App) ExportStats(cfg *Config, isDebug bool, w io.Writer, format export.Format, pageURL string, from, to time.Time) error {
+	// command is interrupted with its db queries.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	logger, err := initLogger(cfg, isDebug)
+	if err != nil {
+		return errors.Wrapf(err, ErrExportStats)
+	}
+
+	rotator, err := newRotator(cfg, isDebug, logger)
+	if err != nil {
+		return errors.Wrapf(err, ErrExportStats)
+	}
+
+	rows, err := rotator.GetExportStats(ctx, pageURL, from, to)
+	if err != nil {
+		return errors.Wrapf(err, ErrExportStats)
+	}
+	if err := export.Write(w, format, rows); err != nil {
+		return errors.Wrapf(err, ErrExportStats)
+	}
+	return nil
+}
+
+// Seed seeds groups, pages, slots and banners of the fixture file and writes what was created, updated or skipped to w.
+func (a *App) Seed(cfg *Config, isDebug bool, w io.Writer, fileName string) error {
+	// command is interrupted with its db queries.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// the command has its own in-memory db which is lost on exit.
+	if cfg.DB.Dialect == memoryDialect {
+		return errors.Errorf("%v: %v dialect can't be seeded by the command, set db.seed to seed it on run", ErrSeed, cfg.DB.Dialect)
+	}
+
+	logger, err := initLogger(cfg, isDebug)
+	if err != nil {
+		return errors.Wrapf(err, ErrSeed)
+	}
+
+	rotator, err := newRotator(cfg, isDebug, logger)
+	if err != nil {
+		return errors.Wrapf(err, ErrSeed)
+	}
+	if err := rotator.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrSeed)
+	}
+
+	report, err := seed(ctx, rotator, fileName)
+	for _, name := range report.Created {
+		fmt.Fprintf(w, "created %v\n", name)
+	}
+	for _, name := range report.Updated {
+		fmt.Fprintf(w, "updated %v\n", name)
+	}
+	for _, name := range report.Skipped {
+		fmt.Fprintf(w, "skipped %v\n", name)
+	}
+	if err != nil {
+		return errors.Wrapf(err, ErrSeed)
+	}
+	fmt.Fprintf(w, "created: %v, updated: %v, skipped: %v\n", len(report.Created), len(report.Updated), len(report.Skipped))
+	return nil
+}
+
+func seed(ctx context.Context, rotator usecase.Rotator, fileName string) (report usecase.SeedReport, err error) {
+	f, err := fixture.Read(fileName)
+	if err != nil {
+		return report, err
+	}
+	return rotator.Seed(ctx, f)
+}
+
+// Apply writes the plan which makes the db equal to the inventory file to w and applies it unless dryRun is set.
+func (a *App) Apply(cfg *Config, isDebug bool, w io.Writer, fileName string, prune, dryRun bool) error {
+	// command is interrupted with its db queries.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// the command has its own in-memory db which is lost on exit.
+	if cfg.DB.Dialect == memoryDialect {
+		return errors.Errorf("%v: %v dialect can't be changed by the command", ErrApply, cfg.DB.Dialect)
+	}
+
+	inventory, err := fixture.ReadInventory(fileName)
+	if err != nil {
+		return errors.Wrapf(err, ErrApply)
+	}
+
+	logger, err := initLogger(cfg, isDebug)
+	if err != nil {
+		return errors.Wrapf(err, ErrApply)
+	}
+
+	rotator, err := newRotator(cfg, isDebug, logger)
+	if err != nil {
+		return errors.Wrapf(err, ErrApply)
+	}
+	if err := rotator.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrApply)
+	}
+
+	plan, err := rotator.PlanInventory(ctx, inventory, prune)
+	if err != nil {
+		return errors.Wrapf(err, ErrApply)
+	}
+	if len(plan) == 0 {
+		fmt.Fprintln(w, "no changes, inventory is up to date")
+		return nil
+	}
+	counts := make(map[usecase.ChangeAction]int)
+	for _, change := range plan {
+		fmt.Fprintln(w, change)
+		counts[change.Action]++
+	}
+	fmt.Fprintf(w, "plan: %v to create, %v to update, %v to delete\n", counts[usecase.ChangeCreate], counts[usecase.ChangeUpdate], counts[usecase.ChangeDelete])
+	if dryRun {
+		return nil
+	}
+
+	if err := rotator.ApplyInventory(ctx, plan); err != nil {
+		return errors.Wrapf(err, ErrApply)
+	}
+	fmt.Fprintln(w, "applied")
+	return nil
+}
+
 func (a *App) DBUp(cfg *Config, isDebug bool) error {
 	logger, err := initLogger(cfg, isDebug)
 	if err != nil {
@@ -145,7 +318,91 @@ func (a *App) DBDown(cfg *Config, isDebug bool) error {
 	return nil
 }
 
-func initRepo(cfg *Config, logger logger.Logger, isDebug bool) (repo *repository.PGRepo, err error) {
+// Migrate applies or reverts the steps migrations or prints the migration status to w.
+// Up applies all not applied migrations, steps are used by down only.
+func (a *App) Migrate(cfg *Config, isDebug bool, w io.Writer, direction string, steps int) error {
+	// command is interrupted with its db queries.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	logger, err := initLogger(cfg, isDebug)
+	if err != nil {
+		return errors.Wrapf(err, ErrMigrate)
+	}
+
+	repo, err := initRepo(cfg, logger, isDebug)
+	if err != nil {
+		return errors.Wrapf(err, ErrMigrate)
+	}
+	m, ok := repo.(migrator)
+	if !ok {
+		return errors.Errorf("%v: %v dialect doesn't support migrations", ErrMigrate, cfg.DB.Dialect)
+	}
+
+	switch direction {
+	case MigrateUp:
+		applied, err := m.MigrateUp(ctx)
+		if err != nil {
+			return errors.Wrapf(err, ErrMigrate)
+		}
+		for _, migration := range applied {
+			fmt.Fprintf(w, "applied %04d_%v\n", migration.Version, migration.Name)
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(w, "no migrations to apply")
+		}
+	case MigrateDown:
+		if steps <= 0 {
+			return errors.Errorf("%v: steps must be positive", ErrMigrate)
+		}
+		reverted, err := m.MigrateDown(ctx, steps)
+		if err != nil {
+			return errors.Wrapf(err, ErrMigrate)
+		}
+		for _, migration := range reverted {
+			fmt.Fprintf(w, "reverted %04d_%v\n", migration.Version, migration.Name)
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(w, "no migrations to revert")
+		}
+	case MigrateStatus:
+		statuses, err := m.GetMigrationStatus(ctx)
+		if err != nil {
+			return errors.Wrapf(err, ErrMigrate)
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d_%v\t%v\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		return errors.Errorf("%v: unknown direction %q, use %v, %v or %v", ErrMigrate, direction, MigrateUp, MigrateDown, MigrateStatus)
+	}
+	return nil
+}
+
+// migrator is the repository with versioned schema migrations.
+type migrator interface {
+	MigrateUp(ctx context.Context) (applied []repository.Migration, err error)
+	MigrateDown(ctx context.Context, steps int) (reverted []repository.Migration, err error)
+	GetMigrationStatus(ctx context.Context) (statuses []repository.MigrationStatus, err error)
+}
+
+// dbRepository is the repository which implements all domain repositories.
+type dbRepository interface {
+	CreateDB()
+	DeleteDB()
+}
+
+func initRepo(cfg *Config, logger logger.Logger, isDebug bool) (repo dbRepository, err error) {
+	if cfg.DB.Dialect == memoryDialect {
+		memRepo := repository.NewMemRepo(logger)
+		// in-memory db is empty on start.
+		memRepo.CreateDB()
+		return memRepo, nil
+	}
 	db, err := initDB(cfg)
 	if err != nil {
 		return nil, errors.Wrapf(err, "can't init repository")
@@ -180,13 +437,30 @@ func initQueueBroker(cfg *Config) (entities.EventQueue, error) {
 }
 
 func initAlgo(cfg *Config) (usecase.NextBannerAlgo, error) {
-	switch cfg.Algo.Name {
+	algo, err := newAlgo(cfg.Algo.Name)
+	if err != nil {
+		return nil, err
+	}
+	pageRouter := router.NewPageRouter(cfg.Algo.Name, algo)
+	// pages can be rotated by other multi-armed bandit algorithms.
+	for _, name := range []string{"ucb1"} {
+		if name == cfg.Algo.Name {
+			continue
+		}
+		algo, err := newAlgo(name)
+		if err != nil {
+			return nil, err
+		}
+		pageRouter.Add(name, algo)
+	}
+	return pageRouter, nil
+}
+
+func newAlgo(name string) (usecase.NextBannerAlgo, error) {
+	switch name {
 	case "ucb1":
 		algo := multiarms.NewUCB1Algo()
 		return algo, nil
-	case "thompson":
-		algo := multiarms.NewUCB1Algo() //todo: realize this algo if necessary
-		return algo, nil
 	case "random":
 		algo := random.NewRandomizer()
 		return algo, nil
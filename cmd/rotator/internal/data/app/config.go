@@ -1,5 +1,7 @@
 package app
 
+import "time"
+
 type Config struct {
 	Log   Log   `yaml:"log"`
 	API   API   `yaml:"api"`
@@ -7,6 +9,7 @@ type Config struct {
 	Algo  Algo  `yaml:"db"`
 	Queue Queue `yaml:"queue"`
 	Kafka Kafka `yaml:"kafka"`
+	Stats Stats `yaml:"stats"`
 }
 type Log struct {
 	File string `yaml:"file"`
@@ -18,8 +21,11 @@ type API struct {
 }
 
 type DB struct {
-	DSN     string `yaml:"dsn"`
+	DSN string `yaml:"dsn"`
+	// Dialect is "postgres", "sqlite3" with the db file path as dsn or "memory" for the in-memory repository.
 	Dialect string `yaml:"dialect"`
+	// Seed is the YAML or JSON fixture file which is seeded by run --updb and on start of the memory dialect.
+	Seed string `yaml:"seed"`
 }
 
 type Algo struct {
@@ -33,3 +39,8 @@ type Kafka struct {
 	Topic string `yaml:"topic"`
 	Addr  string `yaml:"addr"`
 }
+
+type Stats struct {
+	// Interval is the period of pushing stats deltas to subscribers.
+	Interval time.Duration `yaml:"interval"`
+}
@@ -0,0 +1,38 @@
+package grpcservice
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+// errorCode returns the grpc code of the domain error, the gateway maps it to the http status.
+func errorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, entities.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, entities.ErrAlreadyExists):
+		return codes.AlreadyExists
+	case errors.Is(err, entities.ErrInvalidArgument):
+		return codes.InvalidArgument
+	case errors.Is(err, entities.ErrFailedPrecondition):
+		return codes.FailedPrecondition
+	case errors.Is(err, entities.ErrSchemaOutOfDate):
+		// the schema is updated by the next request.
+		return codes.Unavailable
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+func statusError(err error) error {
+	return status.Error(errorCode(err), err.Error())
+}
@@ -0,0 +1,42 @@
+package grpcservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/domain/usecase"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func TestErrorCode(t *testing.T) {
+	tcases := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{name: "not found", err: errors.Wrapf(entities.ErrPageNotFound("site.com"), "can't delete page: %v", "site.com"), code: codes.NotFound},
+		{name: "already exists", err: errors.Wrap(entities.ErrGroupExist("old man"), "can't add new group"), code: codes.AlreadyExists},
+		{name: "invalid argument", err: errors.Wrap(entities.ErrZeroValue(), "can't add slot"), code: codes.InvalidArgument},
+		{name: "failed precondition", err: errors.Wrap(entities.ErrCatalogBannerAttached(1), "can't delete banner"), code: codes.FailedPrecondition},
+		{name: "no banners", err: errors.Wrap(&usecase.AlgoError{}, "can't get next banner"), code: codes.NotFound},
+		{name: "old schema", err: errors.Wrap(&usecase.AlgoError{IsOldSchema: true}, "can't get next banner"), code: codes.Unavailable},
+		{name: "canceled", err: errors.Wrap(context.Canceled, "can't get page"), code: codes.Canceled},
+		{name: "deadline", err: errors.Wrap(context.DeadlineExceeded, "can't get page"), code: codes.DeadlineExceeded},
+		{name: "internal", err: errors.New("connection refused"), code: codes.Internal},
+	}
+	for _, tcase := range tcases {
+		tcase := tcase
+		t.Run(tcase.name, func(t *testing.T) {
+			require.Equal(t, tcase.code, errorCode(tcase.err))
+			st, ok := status.FromError(statusError(tcase.err))
+			require.True(t, ok)
+			require.Equal(t, tcase.code, st.Code())
+			require.Equal(t, tcase.err.Error(), st.Message())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package grpcservice
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/data/export"
+	util "github.com/shipa988/banner_rotator/pkg/request-util"
+)
+
+const exportStatsPath = "/export/stats"
+
+// exportStats serves stats file of the page from the page_url cookie, the same as grpc authorization,
+// query params: format (csv or parquet), from and to in RFC3339.
+func (s *GRPCServer) exportStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cookie, err := r.Cookie(pageURLCookie)
+	if err != nil || cookie.Value == "" {
+		http.Error(w, "pageURL cookie is not set", runtime.HTTPStatusFromCode(codes.NotFound))
+		return
+	}
+	pageURL := cookie.Value
+	ctx = util.SetAutorizationToken(ctx, pageURL)
+	query := r.URL.Query()
+	formatParam := query.Get("format")
+	if formatParam == "" {
+		formatParam = string(export.FormatCSV)
+	}
+	format, err := export.ParseFormat(formatParam)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, err := parseTimeParam(query.Get("from"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeParam(query.Get("to"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rows, err := s.rotator.GetExportStats(ctx, pageURL, from, to)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		http.Error(w, err.Error(), runtime.HTTPStatusFromCode(errorCode(err)))
+		return
+	}
+	// write to buffer to return error status instead of broken file.
+	buf := &bytes.Buffer{}
+	if err := export.Write(buf, format, rows); err != nil {
+		s.logger.Log(ctx, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="stats.%v"`, format))
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		s.logger.Log(ctx, errors.Wrap(err, "can't write stats file"))
+		return
+	}
+	s.logger.Log(ctx, "success")
+}
+
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "can't parse time %v, use RFC3339", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,44 @@
+package grpcservice
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func TestGRPCServer_ExportStats(t *testing.T) {
+	tcases := []struct {
+		name    string
+		cookie  *http.Cookie
+		err     error
+		status  int
+		pageURL string
+	}{
+		{name: "no cookie", status: http.StatusNotFound},
+		{name: "empty cookie", cookie: &http.Cookie{Name: pageURLCookie}, status: http.StatusNotFound},
+		{name: "page", cookie: &http.Cookie{Name: pageURLCookie, Value: "site.com"}, status: http.StatusOK, pageURL: "site.com"},
+		{name: "missing page", cookie: &http.Cookie{Name: pageURLCookie, Value: "other.com"}, err: entities.ErrPageNotFound("other.com"), status: http.StatusNotFound, pageURL: "other.com"},
+	}
+	for _, tcase := range tcases {
+		tcase := tcase
+		t.Run(tcase.name, func(t *testing.T) {
+			rotator := &testRotator{exportErr: tcase.err}
+			s := NewGRPCServer(&sync.WaitGroup{}, zaplogger.NewLogger(ioutil.Discard, false), rotator)
+			req := httptest.NewRequest(http.MethodGet, exportStatsPath+"?page_url=&format=csv", nil)
+			if tcase.cookie != nil {
+				req.AddCookie(tcase.cookie)
+			}
+			w := httptest.NewRecorder()
+			s.exportStats(w, req)
+			require.Equal(t, tcase.status, w.Code)
+			require.Equal(t, tcase.pageURL, rotator.exportPageURL)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +15,7 @@ import (
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/api/httpbody"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -23,13 +25,21 @@ import (
 	api "github.com/shipa988/banner_rotator/cmd/rotator/api"
 	"github.com/shipa988/banner_rotator/cmd/rotator/internal/domain/usecase"
 	"github.com/shipa988/banner_rotator/internal/data/logger"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
 	util "github.com/shipa988/banner_rotator/pkg/request-util"
 )
 
 var _ api.BannerRotatorServiceServer = (*GRPCServer)(nil)
 
 const pageURLCookie = "page_url"
-const defaultTimer = time.Second * 5
+const pixelContentType = "image/gif"
+
+// pixel is a transparent 1x1 gif returned by the view tracking endpoint.
+var pixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
 
 var headers = []string{
 	"Cookie",
@@ -45,51 +55,59 @@ type GRPCServer struct {
 
 func (s *GRPCServer) SubscribeOnEvents(req *api.StatRequest, srv api.BannerRotatorService_SubscribeOnEventsServer) error {
 	pageURL := util.GetAuthorizationToken(srv.Context())
+	slots, deltas, err := s.rotator.SubscribeOnStats(srv.Context(), pageURL)
+	if err != nil {
+		return statusError(err)
+	}
 	stats := []*api.Stat{}
-
-	stop := false
-	for !stop {
-		select {
-		case <-time.After(defaultTimer):
-			slots, err := s.rotator.GetPageStat(pageURL)
-			if err != nil {
-				return status.Error(codes.Aborted, err.Error())
+	for slot, banners := range slots {
+		for banner, events := range banners {
+			for group, event := range events {
+				stats = append(stats, toAPIStat(pageURL, usecase.StatKey{
+					SlotID:           slot.InnerID,
+					BannerID:         banner.InnerID,
+					GroupDescription: group.Description,
+				}, event))
 			}
-			for slot, banners := range slots {
-				for banner, events := range banners {
-					for group, event := range events {
-						stat := &api.Stat{
-							PageUrl:          pageURL,
-							SlotId:           uint64(slot.InnerID),
-							BannerId:         uint64(banner.InnerID),
-							GroupDescription: group.Description,
-							ClickCount:       uint64(event.Clicks),
-							ShowCount:        uint64(event.Shows),
-						}
-						stats = append(stats, stat)
-					}
-				}
-			}
-			resp := &api.StatResponse{Time: ptypes.TimestampNow(), Stat: stats}
-			if err := srv.Send(resp); err != nil {
-				s.logger.Log(srv.Context(), errors.Wrap(err, "stream send error"))
-				stop = true
-			}
-		case <-srv.Context().Done():
-			s.logger.Log(srv.Context(), "stats listener disconnected")
-			stop = true
 		}
 	}
+	if err := srv.Send(&api.StatResponse{Time: ptypes.TimestampNow(), Stat: stats}); err != nil {
+		s.logger.Log(srv.Context(), errors.Wrap(err, "stream send error"))
+		return nil
+	}
 
+	for changed := range deltas {
+		stats := make([]*api.Stat, 0, len(changed))
+		for key, action := range changed {
+			stats = append(stats, toAPIStat(pageURL, key, action))
+		}
+		if err := srv.Send(&api.StatResponse{Time: ptypes.TimestampNow(), Stat: stats, Delta: true}); err != nil {
+			s.logger.Log(srv.Context(), errors.Wrap(err, "stream send error"))
+			return nil
+		}
+	}
+	s.logger.Log(srv.Context(), "stats listener disconnected")
 	return nil
 }
 
+func toAPIStat(pageURL string, key usecase.StatKey, action entities.Action) *api.Stat {
+	return &api.Stat{
+		PageUrl:          pageURL,
+		SlotId:           uint64(key.SlotID),
+		BannerId:         uint64(key.BannerID),
+		GroupDescription: key.GroupDescription,
+		ClickCount:       uint64(action.Clicks),
+		ShowCount:        uint64(action.Shows),
+		ViewCount:        uint64(action.Views),
+	}
+}
+
 func (s *GRPCServer) RegisterSlot(ctx context.Context, req *api.RegisterSlotRequest) (*empty.Empty, error) {
 	pageURL := util.GetAuthorizationToken(ctx)
-	err := s.rotator.AddSlot(pageURL, uint(req.GetSlotId()), req.GetSlotDescription())
+	err := s.rotator.AddSlot(ctx, pageURL, uint(req.GetSlotId()), req.GetSlotDescription(), req.GetViewableTry(), uint(req.GetWidth()), uint(req.GetHeight()))
 	if err != nil {
 		s.logger.Log(ctx, err)
-		return nil, status.Error(codes.Aborted, err.Error())
+		return nil, statusError(err)
 	}
 	s.logger.Log(ctx, "success")
 	return &empty.Empty{}, nil
@@ -97,10 +115,32 @@ func (s *GRPCServer) RegisterSlot(ctx context.Context, req *api.RegisterSlotRequ
 
 func (s *GRPCServer) RegisterBanner(ctx context.Context, req *api.RegisterBannerRequest) (*empty.Empty, error) {
 	pageURL := util.GetAuthorizationToken(ctx)
-	err := s.rotator.AddBannerToSlot(pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()), req.GetBannerDescription())
+	err := s.rotator.AddBannerToSlot(ctx, pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()), req.GetBannerDescription(), fromAPICreative(req.GetCreative()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) UpdateSlot(ctx context.Context, req *api.UpdateSlotRequest) (*empty.Empty, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	err := s.rotator.UpdateSlot(ctx, pageURL, uint(req.GetSlotId()), req.GetSlotDescription(), req.GetViewableTry(), uint(req.GetWidth()), uint(req.GetHeight()))
 	if err != nil {
 		s.logger.Log(ctx, err)
-		return nil, status.Error(codes.Aborted, err.Error())
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) UpdateBanner(ctx context.Context, req *api.UpdateBannerRequest) (*empty.Empty, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	err := s.rotator.UpdateBanner(ctx, pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()), req.GetBannerDescription(), fromAPICreative(req.GetCreative()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
 	}
 	s.logger.Log(ctx, "success")
 	return &empty.Empty{}, nil
@@ -108,10 +148,10 @@ func (s *GRPCServer) RegisterBanner(ctx context.Context, req *api.RegisterBanner
 
 func (s *GRPCServer) DeleteBanner(ctx context.Context, req *api.DeleteBannerRequest) (*empty.Empty, error) {
 	pageURL := util.GetAuthorizationToken(ctx)
-	err := s.rotator.DeleteBannerFromSlot(pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()))
+	err := s.rotator.DeleteBannerFromSlot(ctx, pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()))
 	if err != nil {
 		s.logger.Log(ctx, err)
-		return nil, status.Error(codes.Aborted, err.Error())
+		return nil, statusError(err)
 	}
 	s.logger.Log(ctx, "success")
 	return &empty.Empty{}, nil
@@ -119,10 +159,10 @@ func (s *GRPCServer) DeleteBanner(ctx context.Context, req *api.DeleteBannerRequ
 
 func (s *GRPCServer) DeleteSlot(ctx context.Context, req *api.DeleteSlotRequest) (*empty.Empty, error) {
 	pageURL := util.GetAuthorizationToken(ctx)
-	err := s.rotator.DeleteSlot(pageURL, uint(req.GetSlotId()))
+	err := s.rotator.DeleteSlot(ctx, pageURL, uint(req.GetSlotId()))
 	if err != nil {
 		s.logger.Log(ctx, err)
-		return nil, status.Error(codes.Aborted, err.Error())
+		return nil, statusError(err)
 	}
 	s.logger.Log(ctx, "success")
 	return &empty.Empty{}, nil
@@ -130,10 +170,10 @@ func (s *GRPCServer) DeleteSlot(ctx context.Context, req *api.DeleteSlotRequest)
 
 func (s *GRPCServer) DeleteAllSlots(ctx context.Context, req *api.DeleteAllSlotsRequest) (*empty.Empty, error) {
 	pageURL := util.GetAuthorizationToken(ctx)
-	err := s.rotator.DeleteAllSlots(pageURL)
+	err := s.rotator.DeleteAllSlots(ctx, pageURL)
 	if err != nil {
 		s.logger.Log(ctx, err)
-		return nil, status.Error(codes.Aborted, err.Error())
+		return nil, statusError(err)
 	}
 	s.logger.Log(ctx, "success")
 	return &empty.Empty{}, nil
@@ -141,10 +181,10 @@ func (s *GRPCServer) DeleteAllSlots(ctx context.Context, req *api.DeleteAllSlots
 
 func (s *GRPCServer) DeleteAllBanners(ctx context.Context, req *api.DeleteAllBannersRequest) (*empty.Empty, error) {
 	pageURL := util.GetAuthorizationToken(ctx)
-	err := s.rotator.DeleteAllBannersFormSlot(pageURL, uint(req.GetSlotId()))
+	err := s.rotator.DeleteAllBannersFormSlot(ctx, pageURL, uint(req.GetSlotId()))
 	if err != nil {
 		s.logger.Log(ctx, err)
-		return nil, status.Error(codes.Aborted, err.Error())
+		return nil, statusError(err)
 	}
 	s.logger.Log(ctx, "success")
 	return &empty.Empty{}, nil
@@ -152,27 +192,575 @@ func (s *GRPCServer) DeleteAllBanners(ctx context.Context, req *api.DeleteAllBan
 
 func (s *GRPCServer) ClickEvent(ctx context.Context, req *api.ClickRequest) (*empty.Empty, error) {
 	pageURL := util.GetAuthorizationToken(ctx)
-	err := s.rotator.ClickByBanner(pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()), uint(req.GetUserAge()), req.GetUserSex())
+	err := s.rotator.ClickByBanner(ctx, pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()), uint(req.GetUserAge()), req.GetUserSex(), req.GetAttributes())
 	if err != nil {
 		s.logger.Log(ctx, err)
-		return nil, status.Error(codes.Aborted, err.Error())
+		return nil, statusError(err)
 	}
 	s.logger.Log(ctx, "success")
 	return &empty.Empty{}, nil
 }
 
-func (s *GRPCServer) GetNextBanner(ctx context.Context, req *api.GetNextBannerRequest) (*api.GetNextBannerResponse, error) {
+func (s *GRPCServer) ViewEvent(ctx context.Context, req *api.ViewRequest) (*httpbody.HttpBody, error) {
 	pageURL := util.GetAuthorizationToken(ctx)
-	banner, err := s.rotator.GetNextBanner(pageURL, uint(req.GetSlotId()), uint(req.GetUserAge()), req.GetUserSex())
+	err := s.rotator.ViewBanner(ctx, pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()), uint(req.GetUserAge()), req.GetUserSex(), req.GetAttributes())
 	if err != nil {
 		s.logger.Log(ctx, err)
-		return nil, status.Error(codes.Aborted, err.Error())
+		return nil, statusError(err)
 	}
 	s.logger.Log(ctx, "success")
+	return &httpbody.HttpBody{ContentType: pixelContentType, Data: pixel}, nil
+}
+
+func (s *GRPCServer) GetNextBanner(ctx context.Context, req *api.GetNextBannerRequest) (*api.GetNextBannerResponse, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	banner, err := s.rotator.GetNextBanner(ctx, pageURL, uint(req.GetSlotId()), uint(req.GetUserAge()), req.GetUserSex(), req.GetAttributes(), req.GetPageViewId())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
 	resp := api.GetNextBannerResponse{BannerId: uint64(banner)}
+	if req.GetWithCreative() {
+		b, err := s.rotator.GetBanner(ctx, pageURL, uint(req.GetSlotId()), banner)
+		if err != nil {
+			s.logger.Log(ctx, err)
+			return nil, statusError(err)
+		}
+		resp.Creative = toAPICreative(b.Creative)
+	}
+	s.logger.Log(ctx, "success")
 	return &resp, nil
 }
 
+func (s *GRPCServer) RegisterAdvertiser(ctx context.Context, req *api.RegisterAdvertiserRequest) (*empty.Empty, error) {
+	err := s.rotator.AddAdvertiser(ctx, req.GetAdvertiserName())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) RegisterCampaign(ctx context.Context, req *api.RegisterCampaignRequest) (*empty.Empty, error) {
+	err := s.rotator.AddCampaign(ctx, req.GetAdvertiserName(), req.GetCampaignName())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) AddBannerToCampaign(ctx context.Context, req *api.AddBannerToCampaignRequest) (*empty.Empty, error) {
+	err := s.rotator.AddBannerToCampaign(ctx, req.GetCampaignName(), uint(req.GetBannerId()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) PauseCampaign(ctx context.Context, req *api.CampaignRequest) (*empty.Empty, error) {
+	err := s.rotator.PauseCampaign(ctx, req.GetCampaignName())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) ResumeCampaign(ctx context.Context, req *api.CampaignRequest) (*empty.Empty, error) {
+	err := s.rotator.ResumeCampaign(ctx, req.GetCampaignName())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) PauseAdvertiser(ctx context.Context, req *api.AdvertiserRequest) (*empty.Empty, error) {
+	err := s.rotator.PauseAdvertiser(ctx, req.GetAdvertiserName())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) ResumeAdvertiser(ctx context.Context, req *api.AdvertiserRequest) (*empty.Empty, error) {
+	err := s.rotator.ResumeAdvertiser(ctx, req.GetAdvertiserName())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) GetCampaignStat(ctx context.Context, req *api.CampaignRequest) (*api.RollUpStatResponse, error) {
+	stats, err := s.rotator.GetCampaignStat(ctx, req.GetCampaignName())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &api.RollUpStatResponse{Stat: toAPIGroupStats(stats)}, nil
+}
+
+func (s *GRPCServer) GetAdvertiserStat(ctx context.Context, req *api.AdvertiserRequest) (*api.RollUpStatResponse, error) {
+	stats, err := s.rotator.GetAdvertiserStat(ctx, req.GetAdvertiserName())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &api.RollUpStatResponse{Stat: toAPIGroupStats(stats)}, nil
+}
+
+func (s *GRPCServer) RegisterGroup(ctx context.Context, req *api.RegisterGroupRequest) (*empty.Empty, error) {
+	group, rules := fromAPIGroup(req.GetGroup())
+	err := s.rotator.AddGroup(ctx, group, rules)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) UpdateGroup(ctx context.Context, req *api.UpdateGroupRequest) (*empty.Empty, error) {
+	group, rules := fromAPIGroup(req.GetGroup())
+	// the api doesn't set the default group, the updated group keeps being default.
+	_, defaultGroupDescription, err := s.rotator.GetGroups(ctx)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	group.IsDefault = req.GetGroupDescription() == defaultGroupDescription
+	err = s.rotator.UpdateGroup(ctx, req.GetGroupDescription(), group, rules)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) DeleteGroup(ctx context.Context, req *api.DeleteGroupRequest) (*empty.Empty, error) {
+	err := s.rotator.DeleteGroup(ctx, req.GetGroupDescription())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) ListGroups(ctx context.Context, req *empty.Empty) (*api.ListGroupsResponse, error) {
+	groups, defaultGroupDescription, err := s.rotator.GetGroups(ctx)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	rules, err := s.rotator.GetGroupRules(ctx)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	resp := &api.ListGroupsResponse{DefaultGroupDescription: defaultGroupDescription}
+	for _, group := range groups {
+		resp.Groups = append(resp.Groups, toAPIGroup(group, rules[group.Description]))
+	}
+	s.logger.Log(ctx, "success")
+	return resp, nil
+}
+
+func (s *GRPCServer) SetBannerTargeting(ctx context.Context, req *api.SetBannerTargetingRequest) (*empty.Empty, error) {
+	t := req.GetTargeting()
+	targeting := entities.Targeting{
+		IncludeGroups: t.GetIncludeGroups(),
+		ExcludeGroups: t.GetExcludeGroups(),
+		MinAge:        uint(t.GetMinAge()),
+		MaxAge:        uint(t.GetMaxAge()),
+		Sex:           t.GetSex(),
+	}
+	err := s.rotator.SetBannerTargeting(ctx, uint(req.GetBannerId()), targeting)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) DeleteBannerTargeting(ctx context.Context, req *api.BannerTargetingRequest) (*empty.Empty, error) {
+	err := s.rotator.DeleteBannerTargeting(ctx, uint(req.GetBannerId()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) GetBannerTargeting(ctx context.Context, req *api.BannerTargetingRequest) (*api.Targeting, error) {
+	targeting, err := s.rotator.GetBannerTargeting(ctx, uint(req.GetBannerId()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &api.Targeting{
+		IncludeGroups: targeting.IncludeGroups,
+		ExcludeGroups: targeting.ExcludeGroups,
+		MinAge:        uint64(targeting.MinAge),
+		MaxAge:        uint64(targeting.MaxAge),
+		Sex:           targeting.Sex,
+	}, nil
+}
+
+func (s *GRPCServer) SetBannerTags(ctx context.Context, req *api.SetBannerTagsRequest) (*empty.Empty, error) {
+	tags := entities.Tags{
+		Categories:  req.GetTags().GetCategories(),
+		Competitors: req.GetTags().GetCompetitors(),
+	}
+	err := s.rotator.SetBannerTags(ctx, uint(req.GetBannerId()), tags)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) GetBannerTags(ctx context.Context, req *api.BannerTagsRequest) (*api.Tags, error) {
+	tags, err := s.rotator.GetBannerTags(ctx, uint(req.GetBannerId()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &api.Tags{Categories: tags.Categories, Competitors: tags.Competitors}, nil
+}
+
+func (s *GRPCServer) CreatePage(ctx context.Context, req *api.Page) (*empty.Empty, error) {
+	err := s.rotator.AddPage(ctx, req.GetPageUrl(), fromAPIPageSettings(req.GetSettings()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) RenamePage(ctx context.Context, req *api.RenamePageRequest) (*empty.Empty, error) {
+	err := s.rotator.RenamePage(ctx, req.GetPageUrl(), req.GetNewPageUrl())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) SetPageSettings(ctx context.Context, req *api.SetPageSettingsRequest) (*empty.Empty, error) {
+	err := s.rotator.SetPageSettings(ctx, req.GetPageUrl(), fromAPIPageSettings(req.GetSettings()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) ListPages(ctx context.Context, req *empty.Empty) (*api.ListPagesResponse, error) {
+	pages, err := s.rotator.GetPages(ctx)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	resp := &api.ListPagesResponse{}
+	for pageURL, settings := range pages {
+		resp.Pages = append(resp.Pages, &api.Page{
+			PageUrl: pageURL,
+			Settings: &api.PageSettings{
+				Algorithm:         settings.Algorithm,
+				AllowedCategories: settings.AllowedCategories,
+			},
+		})
+	}
+	sort.Slice(resp.Pages, func(i, j int) bool {
+		return resp.Pages[i].PageUrl < resp.Pages[j].PageUrl
+	})
+	s.logger.Log(ctx, "success")
+	return resp, nil
+}
+
+func (s *GRPCServer) DeletePage(ctx context.Context, req *api.DeletePageRequest) (*empty.Empty, error) {
+	err := s.rotator.DeletePage(ctx, req.GetPageUrl())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) ListSlots(ctx context.Context, req *api.ListSlotsRequest) (*api.ListSlotsResponse, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	slots, err := s.rotator.GetSlotsByPageURL(ctx, pageURL)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	resp := &api.ListSlotsResponse{}
+	for _, slot := range slots {
+		resp.Slots = append(resp.Slots, &api.Slot{
+			SlotId:          uint64(slot.InnerID),
+			SlotDescription: slot.Description,
+			ViewableTry:     slot.ViewableTry,
+			Width:           uint64(slot.Width),
+			Height:          uint64(slot.Height),
+		})
+	}
+	s.logger.Log(ctx, "success")
+	return resp, nil
+}
+
+func (s *GRPCServer) ListBanners(ctx context.Context, req *api.ListBannersRequest) (*api.ListBannersResponse, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	banners, err := s.rotator.GetBannerInfos(ctx, pageURL, uint(req.GetSlotId()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	resp := &api.ListBannersResponse{}
+	for _, banner := range banners {
+		resp.Banners = append(resp.Banners, toAPIBanner(banner))
+	}
+	s.logger.Log(ctx, "success")
+	return resp, nil
+}
+
+func (s *GRPCServer) GetBanner(ctx context.Context, req *api.GetBannerRequest) (*api.Banner, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	banner, err := s.rotator.GetBannerInfo(ctx, pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return toAPIBanner(*banner), nil
+}
+
+func (s *GRPCServer) CreateCatalogBanner(ctx context.Context, req *api.CatalogBanner) (*empty.Empty, error) {
+	err := s.rotator.AddCatalogBanner(ctx, uint(req.GetBannerId()), req.GetBannerDescription(), fromAPICreative(req.GetCreative()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) ListCatalogBanners(ctx context.Context, req *empty.Empty) (*api.ListCatalogBannersResponse, error) {
+	banners, err := s.rotator.GetCatalogBanners(ctx)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	resp := &api.ListCatalogBannersResponse{}
+	for _, banner := range banners {
+		resp.Banners = append(resp.Banners, &api.CatalogBanner{
+			BannerId:          uint64(banner.InnerID),
+			BannerDescription: banner.Description,
+			Creative:          toAPICreative(banner.Creative),
+		})
+	}
+	s.logger.Log(ctx, "success")
+	return resp, nil
+}
+
+func (s *GRPCServer) DeleteCatalogBanner(ctx context.Context, req *api.CatalogBannerRequest) (*empty.Empty, error) {
+	err := s.rotator.DeleteCatalogBanner(ctx, uint(req.GetBannerId()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) AttachBanner(ctx context.Context, req *api.AttachBannerRequest) (*empty.Empty, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	err := s.rotator.AttachBanner(ctx, pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) DetachBanner(ctx context.Context, req *api.AttachBannerRequest) (*empty.Empty, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	err := s.rotator.DetachBanner(ctx, pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &empty.Empty{}, nil
+}
+
+func (s *GRPCServer) GetCatalogBannerStat(ctx context.Context, req *api.CatalogBannerRequest) (*api.RollUpStatResponse, error) {
+	stats, err := s.rotator.GetBannerStat(ctx, uint(req.GetBannerId()))
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	s.logger.Log(ctx, "success")
+	return &api.RollUpStatResponse{Stat: toAPIGroupStats(stats)}, nil
+}
+
+func (s *GRPCServer) GetStatHistory(ctx context.Context, req *api.StatHistoryRequest) (*api.StatHistoryResponse, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	var from, to time.Time
+	if req.GetFrom() != nil {
+		from = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		to = req.GetTo().AsTime()
+	}
+	granularity := entities.Granularity(req.GetGranularity())
+	if granularity == "" {
+		granularity = entities.GranularityHour
+	}
+	buckets, err := s.rotator.GetStatHistory(ctx, pageURL, uint(req.GetSlotId()), uint(req.GetBannerId()), from, to, granularity)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	resp := &api.StatHistoryResponse{}
+	for _, bucket := range buckets {
+		t, err := ptypes.TimestampProto(bucket.Start)
+		if err != nil {
+			s.logger.Log(ctx, err)
+			return nil, statusError(err)
+		}
+		resp.Buckets = append(resp.Buckets, &api.StatBucket{
+			Time:             t,
+			SlotId:           uint64(bucket.SlotID),
+			BannerId:         uint64(bucket.BannerID),
+			GroupDescription: bucket.GroupDescription,
+			ClickCount:       uint64(bucket.Clicks),
+			ShowCount:        uint64(bucket.Shows),
+			ViewCount:        uint64(bucket.Views),
+		})
+	}
+	s.logger.Log(ctx, "success")
+	return resp, nil
+}
+
+func (s *GRPCServer) GetStats(ctx context.Context, req *api.GetStatsRequest) (*api.GetStatsResponse, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	filter := entities.StatFilter{
+		SlotID:           uint(req.GetSlotId()),
+		BannerID:         uint(req.GetBannerId()),
+		GroupDescription: req.GetGroupDescription(),
+	}
+	if req.GetFrom() != nil {
+		filter.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		filter.To = req.GetTo().AsTime()
+	}
+	groupBy := make([]entities.StatDimension, 0, len(req.GetGroupBy()))
+	for _, dimension := range req.GetGroupBy() {
+		groupBy = append(groupBy, entities.StatDimension(dimension))
+	}
+	rows, err := s.rotator.GetStats(ctx, pageURL, filter, groupBy)
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	resp := &api.GetStatsResponse{}
+	for _, row := range rows {
+		resp.Rows = append(resp.Rows, &api.StatRow{
+			SlotId:           uint64(row.SlotID),
+			BannerId:         uint64(row.BannerID),
+			GroupDescription: row.GroupDescription,
+			ClickCount:       uint64(row.Clicks),
+			ShowCount:        uint64(row.Shows),
+			ViewCount:        uint64(row.Views),
+			Ctr:              row.CTR,
+		})
+	}
+	s.logger.Log(ctx, "success")
+	return resp, nil
+}
+
+func (s *GRPCServer) GetSignificance(ctx context.Context, req *api.GetSignificanceRequest) (*api.GetSignificanceResponse, error) {
+	pageURL := util.GetAuthorizationToken(ctx)
+	rows, err := s.rotator.GetSignificance(ctx, pageURL, uint(req.GetSlotId()), req.GetConfidence())
+	if err != nil {
+		s.logger.Log(ctx, err)
+		return nil, statusError(err)
+	}
+	resp := &api.GetSignificanceResponse{}
+	for _, row := range rows {
+		resp.Banners = append(resp.Banners, &api.BannerSignificance{
+			SlotId:              uint64(row.SlotID),
+			GroupDescription:    row.GroupDescription,
+			BannerId:            uint64(row.BannerID),
+			TryCount:            uint64(row.Tries),
+			ClickCount:          uint64(row.Clicks),
+			Ctr:                 row.CTR,
+			CtrLow:              row.CTRLow,
+			CtrHigh:             row.CTRHigh,
+			ProbabilityToBeBest: row.ProbabilityToBeBest,
+		})
+	}
+	s.logger.Log(ctx, "success")
+	return resp, nil
+}
+
+func toAPIBanner(b usecase.BannerInfo) *api.Banner {
+	banner := &api.Banner{
+		BannerId:          uint64(b.InnerID),
+		BannerDescription: b.Description,
+		Creative:          toAPICreative(b.Creative),
+	}
+	for group, action := range b.Stats {
+		banner.ClickCount += uint64(action.Clicks)
+		banner.ShowCount += uint64(action.Shows)
+		banner.ViewCount += uint64(action.Views)
+		banner.Groups = append(banner.Groups, &api.BannerGroupStat{
+			GroupDescription: group.Description,
+			ClickCount:       uint64(action.Clicks),
+			ShowCount:        uint64(action.Shows),
+			ViewCount:        uint64(action.Views),
+			Score:            b.Scores[group.Description],
+		})
+	}
+	sort.Slice(banner.Groups, func(i, j int) bool {
+		return banner.Groups[i].GroupDescription < banner.Groups[j].GroupDescription
+	})
+	return banner
+}
+
+func fromAPIPageSettings(settings *api.PageSettings) entities.PageSettings {
+	return entities.PageSettings{
+		Algorithm:         settings.GetAlgorithm(),
+		AllowedCategories: settings.GetAllowedCategories(),
+	}
+}
+
 func NewGRPCServer(wg *sync.WaitGroup, logger logger.Logger, rotator usecase.Rotator) *GRPCServer {
 	return &GRPCServer{
 		logger:  logger,
@@ -190,6 +778,8 @@ func (s *GRPCServer) ServeGW(addr string, addrgw string) error {
 
 	mux := runtime.NewServeMux(
 		runtime.WithMetadata(injectHeadersIntoMetadata),
+		// serve view pixel as raw image.
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.HTTPBodyMarshaler{Marshaler: &runtime.JSONPb{OrigName: true}}),
 	)
 	opts := []grpc.DialOption{grpc.WithInsecure()}
 
@@ -197,9 +787,12 @@ func (s *GRPCServer) ServeGW(addr string, addrgw string) error {
 	if err != nil {
 		return errors.Wrapf(err, "can't register gateway from grpc endpoint at addr %v", addr)
 	}
+	root := http.NewServeMux()
+	root.HandleFunc(exportStatsPath, s.exportStats)
+	root.Handle("/", mux)
 	s.gwserver = &http.Server{
 		Addr:    addrgw,
-		Handler: mux,
+		Handler: root,
 	}
 
 	if err := s.gwserver.ListenAndServe(); err != http.ErrServerClosed {
@@ -328,6 +921,78 @@ func (s *GRPCServer) logRequest(ctx context.Context, ri *util.HTTPReqInfo) {
 	s.logger.Log(ctx, "%s [%s] %s %s %s %s %s [%s]", ri.IP, ri.Start, ri.Method, ri.Path, ri.Httpver, ri.Code, ri.Latency, ri.Useragent)
 }
 
+func fromAPICreative(c *api.Creative) entities.Creative {
+	return entities.Creative{
+		AssetURL:  c.GetAssetUrl(),
+		Width:     uint(c.GetWidth()),
+		Height:    uint(c.GetHeight()),
+		AltText:   c.GetAltText(),
+		TargetURL: c.GetTargetUrl(),
+		MIMEType:  c.GetMimeType(),
+	}
+}
+
+func toAPICreative(c entities.Creative) *api.Creative {
+	return &api.Creative{
+		AssetUrl:  c.AssetURL,
+		Width:     uint64(c.Width),
+		Height:    uint64(c.Height),
+		AltText:   c.AltText,
+		TargetUrl: c.TargetURL,
+		MimeType:  c.MIMEType,
+	}
+}
+
+func fromAPIGroup(g *api.Group) (entities.Group, []entities.Rule) {
+	group := entities.Group{
+		Description: g.GetDescription(),
+		Sex:         g.GetSex(),
+		MinAge:      uint(g.GetMinAge()),
+		MaxAge:      uint(g.GetMaxAge()),
+		Priority:    int(g.GetPriority()),
+	}
+	rules := make([]entities.Rule, 0, len(g.GetRules()))
+	for _, rule := range g.GetRules() {
+		rules = append(rules, entities.Rule{
+			Attribute: rule.GetAttribute(),
+			Operator:  rule.GetOperator(),
+			Value:     rule.GetValue(),
+		})
+	}
+	return group, rules
+}
+
+func toAPIGroup(group entities.Group, rules []entities.Rule) *api.Group {
+	g := &api.Group{
+		Description: group.Description,
+		Sex:         group.Sex,
+		MinAge:      uint64(group.MinAge),
+		MaxAge:      uint64(group.MaxAge),
+		Priority:    int64(group.Priority),
+	}
+	for _, rule := range rules {
+		g.Rules = append(g.Rules, &api.Rule{
+			Attribute: rule.Attribute,
+			Operator:  rule.Operator,
+			Value:     rule.Value,
+		})
+	}
+	return g
+}
+
+func toAPIGroupStats(stats usecase.GroupStats) []*api.GroupStat {
+	groupStats := make([]*api.GroupStat, 0, len(stats))
+	for group, action := range stats {
+		groupStats = append(groupStats, &api.GroupStat{
+			GroupDescription: group.Description,
+			ClickCount:       uint64(action.Clicks),
+			ShowCount:        uint64(action.Shows),
+			ViewCount:        uint64(action.Views),
+		})
+	}
+	return groupStats
+}
+
 func injectHeadersIntoMetadata(ctx context.Context, req *http.Request) metadata.MD {
 	pairs := make([]string, 0, len(headers))
 	for _, h := range headers {
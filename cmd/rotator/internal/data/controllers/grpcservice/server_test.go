@@ -0,0 +1,68 @@
+package grpcservice
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/shipa988/banner_rotator/cmd/rotator/api"
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/domain/usecase"
+	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+// testRotator implements only methods called by tests, others panic.
+type testRotator struct {
+	usecase.Rotator
+	exportPageURL string
+	exportErr     error
+	nextErr       error
+}
+
+func (r *testRotator) GetExportStats(ctx context.Context, pageURL string, from, to time.Time) ([]entities.PageStatRow, error) {
+	r.exportPageURL = pageURL
+	if r.exportErr != nil {
+		return nil, r.exportErr
+	}
+	return []entities.PageStatRow{{PageURL: pageURL}}, nil
+}
+
+func (r *testRotator) GetNextBanner(ctx context.Context, pageURL string, slotID, userAge uint, userSex string, attributes map[string]string, pageViewID string) (uint, error) {
+	if r.nextErr != nil {
+		return 0, r.nextErr
+	}
+	return 1, nil
+}
+
+func TestGRPCServer_GatewayStatus(t *testing.T) {
+	tcases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "slot not found", err: errors.Wrap(entities.ErrSlotNotFound(1, "site.com"), "can't get next banner"), status: http.StatusNotFound},
+		{name: "schema out of date", err: errors.Wrap(&usecase.AlgoError{IsOldSchema: true}, "can't get next banner"), status: http.StatusServiceUnavailable},
+		{name: "invalid argument", err: entities.ErrZeroValue(), status: http.StatusBadRequest},
+		{name: "internal", err: errors.New("connection refused"), status: http.StatusInternalServerError},
+	}
+	for _, tcase := range tcases {
+		tcase := tcase
+		t.Run(tcase.name, func(t *testing.T) {
+			s := NewGRPCServer(&sync.WaitGroup{}, zaplogger.NewLogger(ioutil.Discard, false), &testRotator{nextErr: tcase.err})
+			mux := runtime.NewServeMux()
+			require.NoError(t, api.RegisterBannerRotatorServiceHandlerServer(context.Background(), mux, s))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/site.com/1", nil))
+			require.Equal(t, tcase.status, w.Code)
+		})
+	}
+}
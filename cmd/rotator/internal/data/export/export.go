@@ -0,0 +1,109 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+// Format is the file format of exported statistics.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+var header = []string{"page_url", "slot_id", "banner_id", "group_description", "shows", "clicks", "views", "ctr"}
+
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case FormatCSV, FormatParquet:
+		return Format(format), nil
+	default:
+		return "", errors.Errorf("unknown export format %v, use csv or parquet", format)
+	}
+}
+
+func (f Format) ContentType() string {
+	if f == FormatParquet {
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv"
+}
+
+// Write writes statistics rows to w in the format.
+func Write(w io.Writer, format Format, rows []entities.PageStatRow) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, rows)
+	case FormatParquet:
+		return writeParquet(w, rows)
+	default:
+		return errors.Errorf("unknown export format %v, use csv or parquet", format)
+	}
+}
+
+func writeCSV(w io.Writer, rows []entities.PageStatRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return errors.Wrap(err, "can't write csv header")
+	}
+	for _, row := range rows {
+		record := []string{
+			row.PageURL,
+			strconv.FormatUint(uint64(row.SlotID), 10),
+			strconv.FormatUint(uint64(row.BannerID), 10),
+			row.GroupDescription,
+			strconv.FormatUint(uint64(row.Shows), 10),
+			strconv.FormatUint(uint64(row.Clicks), 10),
+			strconv.FormatUint(uint64(row.Views), 10),
+			strconv.FormatFloat(row.CTR, 'f', -1, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return errors.Wrap(err, "can't write csv record")
+		}
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "can't flush csv")
+}
+
+type parquetRow struct {
+	PageURL          string  `parquet:"name=page_url, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SlotID           int64   `parquet:"name=slot_id, type=INT64"`
+	BannerID         int64   `parquet:"name=banner_id, type=INT64"`
+	GroupDescription string  `parquet:"name=group_description, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Shows            int64   `parquet:"name=shows, type=INT64"`
+	Clicks           int64   `parquet:"name=clicks, type=INT64"`
+	Views            int64   `parquet:"name=views, type=INT64"`
+	CTR              float64 `parquet:"name=ctr, type=DOUBLE"`
+}
+
+func writeParquet(w io.Writer, rows []entities.PageStatRow) error {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(parquetRow), 1)
+	if err != nil {
+		return errors.Wrap(err, "can't create parquet writer")
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	for _, row := range rows {
+		if err := pw.Write(parquetRow{
+			PageURL:          row.PageURL,
+			SlotID:           int64(row.SlotID),
+			BannerID:         int64(row.BannerID),
+			GroupDescription: row.GroupDescription,
+			Shows:            int64(row.Shows),
+			Clicks:           int64(row.Clicks),
+			Views:            int64(row.Views),
+			CTR:              row.CTR,
+		}); err != nil {
+			return errors.Wrap(err, "can't write parquet row")
+		}
+	}
+	return errors.Wrap(pw.WriteStop(), "can't finish parquet file")
+}
@@ -0,0 +1,60 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func TestWrite(t *testing.T) {
+	rows := []entities.PageStatRow{
+		{
+			PageURL: "mysite.com",
+			StatRow: entities.StatRow{
+				SlotID:           1,
+				BannerID:         2,
+				GroupDescription: "old man",
+				Action:           entities.Action{Clicks: 1, Shows: 4},
+				CTR:              0.25,
+			},
+		},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, Write(buf, FormatCSV, rows))
+		require.Equal(t, "page_url,slot_id,banner_id,group_description,shows,clicks,views,ctr\nmysite.com,1,2,old man,4,1,0,0.25\n", buf.String())
+	})
+
+	t.Run("parquet", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, Write(buf, FormatParquet, rows))
+		file, err := buffer.NewBufferFile(buf.Bytes())
+		require.NoError(t, err)
+		pr, err := reader.NewParquetReader(file, new(parquetRow), 1)
+		require.NoError(t, err)
+		defer pr.ReadStop()
+		require.Equal(t, int64(len(rows)), pr.GetNumRows())
+		read := make([]parquetRow, pr.GetNumRows())
+		require.NoError(t, pr.Read(&read))
+		require.Equal(t, []parquetRow{{
+			PageURL:          "mysite.com",
+			SlotID:           1,
+			BannerID:         2,
+			GroupDescription: "old man",
+			Shows:            4,
+			Clicks:           1,
+			CTR:              0.25,
+		}}, read)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := ParseFormat("xlsx")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,45 @@
+package fixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/domain/usecase"
+)
+
+// Read reads the fixture from the file, files with .json extension are read as JSON, others as YAML.
+func Read(fileName string) (fixture usecase.Fixture, err error) {
+	err = read(fileName, &fixture)
+	return fixture, err
+}
+
+// ReadInventory reads the inventory from the file in the same formats as the fixture.
+func ReadInventory(fileName string) (inventory usecase.Inventory, err error) {
+	err = read(fileName, &inventory)
+	return inventory, err
+}
+
+func read(fileName string, v interface{}) (err error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "can't read file %v", fileName)
+	}
+	if strings.EqualFold(filepath.Ext(fileName), ".json") {
+		// unknown fields are rejected like in YAML.
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(v)
+	} else {
+		err = yaml.UnmarshalStrict(data, v)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "can't parse file %v", fileName)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package fixture
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/cmd/rotator/internal/domain/usecase"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func TestRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixture")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	write := func(name, data string) string {
+		fileName := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(fileName, []byte(data), 0600))
+		return fileName
+	}
+	expected := usecase.Fixture{
+		Groups: []usecase.FixtureGroup{
+			{Description: "vip", MinAge: 20, MaxAge: 30, Rules: []entities.Rule{{Attribute: "plan", Operator: entities.RuleEqual, Value: "gold"}}},
+		},
+		Pages: []usecase.FixturePage{
+			{URL: "mysite.com", Slots: []usecase.FixtureSlot{
+				{ID: 1, Description: "top", Width: 300, Banners: []usecase.FixtureBanner{
+					{ID: 2, Description: "sale", Creative: entities.Creative{AssetURL: "https://cdn.com/sale.png", Width: 300}},
+				}},
+			}},
+		},
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		fixture, err := Read(write("seed.yaml", `
+groups:
+  - description: vip
+    minage: 20
+    maxage: 30
+    rules:
+      - attribute: plan
+        operator: eq
+        value: gold
+pages:
+  - url: mysite.com
+    slots:
+      - id: 1
+        description: top
+        width: 300
+        banners:
+          - id: 2
+            description: sale
+            creative:
+              asseturl: https://cdn.com/sale.png
+              width: 300
+`))
+		require.NoError(t, err)
+		require.Equal(t, expected, fixture)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		fixture, err := Read(write("seed.json", `{
+  "groups": [{"description": "vip", "minage": 20, "maxage": 30, "rules": [{"attribute": "plan", "operator": "eq", "value": "gold"}]}],
+  "pages": [{"url": "mysite.com", "slots": [{"id": 1, "description": "top", "width": 300,
+    "banners": [{"id": 2, "description": "sale", "creative": {"assetURL": "https://cdn.com/sale.png", "width": 300}}]}]}]
+}`))
+		require.NoError(t, err)
+		require.Equal(t, expected, fixture)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := Read(write("typo.yaml", "groups:\n  - descripton: vip\n"))
+		require.Error(t, err)
+		_, err = Read(write("typo.json", `{"groups": [{"descripton": "vip"}]}`))
+		require.Error(t, err)
+	})
+
+	t.Run("inventory", func(t *testing.T) {
+		inventory, err := ReadInventory(write("inventory.yaml", "pages:\n  - url: mysite.com\n"))
+		require.NoError(t, err)
+		require.Equal(t, usecase.Inventory{Pages: []usecase.FixturePage{{URL: "mysite.com"}}}, inventory)
+
+		// groups are seeded by the fixture only.
+		_, err = ReadInventory(write("groups.yaml", "groups:\n  - description: vip\n"))
+		require.Error(t, err)
+		_, err = ReadInventory(write("groups.json", `{"groups": [{"description": "vip"}]}`))
+		require.Error(t, err)
+	})
+}
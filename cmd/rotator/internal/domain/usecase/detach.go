@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"time"
+)
+
+// detachedContext keeps values of the request context (e.g. request id) but not its cancellation,
+// so events are pushed after the rpc is done.
+type detachedContext struct {
+	context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
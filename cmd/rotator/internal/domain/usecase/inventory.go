@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+const (
+	ErrPlanInventory  = "can't plan inventory changes"
+	ErrApplyInventory = "can't apply inventory change: %v"
+)
+
+// Inventory is the desired state of pages, slots and banners, groups are seeded by the fixture.
+type Inventory struct {
+	Pages []FixturePage `yaml:"pages" json:"pages"`
+}
+
+type ChangeAction string
+
+const (
+	ChangeCreate ChangeAction = "create"
+	ChangeUpdate ChangeAction = "update"
+	ChangeDelete ChangeAction = "delete"
+)
+
+type ChangeKind string
+
+const (
+	KindPage   ChangeKind = "page"
+	KindSlot   ChangeKind = "slot"
+	KindBanner ChangeKind = "banner"
+)
+
+// Change is one step of the inventory plan, fields which are not used by the kind are zero.
+type Change struct {
+	Action   ChangeAction
+	Kind     ChangeKind
+	PageURL  string
+	Settings entities.PageSettings
+	Slot     entities.Slot
+	Banner   entities.Banner
+}
+
+func (c Change) String() string {
+	sign := map[ChangeAction]string{ChangeCreate: "+", ChangeUpdate: "~", ChangeDelete: "-"}[c.Action]
+	switch c.Kind {
+	case KindSlot:
+		return fmt.Sprintf("%v slot %v/%v", sign, c.PageURL, c.Slot.InnerID)
+	case KindBanner:
+		return fmt.Sprintf("%v banner %v/%v/%v", sign, c.PageURL, c.Slot.InnerID, c.Banner.InnerID)
+	default:
+		return fmt.Sprintf("%v page %v", sign, c.PageURL)
+	}
+}
+
+// PlanInventory returns changes which make pages, slots and banners equal to the inventory.
+// Objects which are absent in the inventory are deleted only with prune,
+// deleted page or slot deletes its slots and banners too.
+func (r *RotatorInteractor) PlanInventory(ctx context.Context, inventory Inventory, prune bool) (plan []Change, err error) {
+	trees, err := r.treeRepo.GetPageTrees(ctx, "")
+	if err != nil {
+		return nil, errors.Wrap(err, ErrPlanInventory)
+	}
+	settings, err := r.pageRepo.GetPageSettings(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrPlanInventory)
+	}
+	if err := checkSharedBanners(inventory, trees, prune); err != nil {
+		return nil, errors.Wrap(err, ErrPlanInventory)
+	}
+	existing := make(map[string]entities.PageTree, len(trees))
+	for _, tree := range trees {
+		existing[tree.URL] = tree
+	}
+
+	desired := make(map[string]bool, len(inventory.Pages))
+	for _, page := range inventory.Pages {
+		if desired[page.URL] {
+			return nil, errors.Wrap(errors.Errorf("page %v is duplicated", page.URL), ErrPlanInventory)
+		}
+		desired[page.URL] = true
+		tree, ok := existing[page.URL]
+		switch {
+		case !ok:
+			plan = append(plan, Change{Action: ChangeCreate, Kind: KindPage, PageURL: page.URL, Settings: page.settings()})
+		case !equalSettings(settings[page.URL], page.settings()):
+			plan = append(plan, Change{Action: ChangeUpdate, Kind: KindPage, PageURL: page.URL, Settings: page.settings()})
+		}
+		changes, err := planSlots(page, tree.Slots, prune)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrPlanInventory)
+		}
+		plan = append(plan, changes...)
+	}
+
+	if prune {
+		var urls []string
+		for url := range existing {
+			if !desired[url] {
+				urls = append(urls, url)
+			}
+		}
+		sort.Strings(urls)
+		for _, url := range urls {
+			plan = append(plan, Change{Action: ChangeDelete, Kind: KindPage, PageURL: url})
+		}
+	}
+	return plan, nil
+}
+
+type bannerKey struct {
+	innerID     uint
+	description string
+}
+
+type slotKey struct {
+	pageURL string
+	slotID  uint
+}
+
+// checkSharedBanners fails if slots which share the existing banner want it different,
+// the banner update changes it in all its slots. Banners which aren't in the inventory are kept unless pruned.
+func checkSharedBanners(inventory Inventory, trees []entities.PageTree, prune bool) error {
+	desired := make(map[slotKey]map[uint]entities.Banner)
+	for _, page := range inventory.Pages {
+		for _, fixtureSlot := range page.Slots {
+			banners := make(map[uint]entities.Banner, len(fixtureSlot.Banners))
+			for _, fixtureBanner := range fixtureSlot.Banners {
+				banners[fixtureBanner.ID] = fixtureBanner.banner()
+			}
+			desired[slotKey{pageURL: page.URL, slotID: fixtureSlot.ID}] = banners
+		}
+	}
+	wanted := make(map[bannerKey]entities.Banner)
+	wantedBy := make(map[bannerKey]slotKey)
+	for _, tree := range trees {
+		for _, slot := range tree.Slots {
+			current := slotKey{pageURL: tree.URL, slotID: slot.InnerID}
+			for _, b := range slot.Banners {
+				want, ok := desired[current][b.InnerID]
+				if !ok {
+					if prune {
+						continue
+					}
+					want = b.Banner
+				}
+				key := bannerKey{innerID: b.InnerID, description: b.Description}
+				other, ok := wanted[key]
+				if ok && other != want {
+					by := wantedBy[key]
+					return errors.Errorf("banner %v/%v/%v is shared with slot %v/%v and should be equal in both", tree.URL, slot.InnerID, b.InnerID, by.pageURL, by.slotID)
+				}
+				wanted[key] = want
+				wantedBy[key] = current
+			}
+		}
+	}
+	return nil
+}
+
+func planSlots(page FixturePage, slotTrees []entities.SlotTree, prune bool) (plan []Change, err error) {
+	existing := make(map[uint]entities.SlotTree, len(slotTrees))
+	for _, tree := range slotTrees {
+		existing[tree.InnerID] = tree
+	}
+	desired := make(map[uint]bool, len(page.Slots))
+	for _, fixtureSlot := range page.Slots {
+		if desired[fixtureSlot.ID] {
+			return nil, errors.Errorf("slot %v/%v is duplicated", page.URL, fixtureSlot.ID)
+		}
+		desired[fixtureSlot.ID] = true
+		slot := fixtureSlot.slot()
+		tree, ok := existing[slot.InnerID]
+		banners, err := planBanners(page.URL, fixtureSlot, tree.Banners, prune)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !ok:
+			plan = append(plan, Change{Action: ChangeCreate, Kind: KindSlot, PageURL: page.URL, Slot: slot})
+			plan = append(plan, banners...)
+		case tree.Slot != slot:
+			plan = append(plan, planSlotUpdate(page.URL, tree.Slot, slot, banners)...)
+		default:
+			plan = append(plan, banners...)
+		}
+	}
+
+	if prune {
+		for _, tree := range slotTrees {
+			if !desired[tree.InnerID] {
+				plan = append(plan, Change{Action: ChangeDelete, Kind: KindSlot, PageURL: page.URL, Slot: tree.Slot})
+			}
+		}
+	}
+	return plan, nil
+}
+
+func planBanners(pageURL string, fixtureSlot FixtureSlot, bannerTrees []entities.BannerTree, prune bool) (plan []Change, err error) {
+	slot := fixtureSlot.slot()
+	desired := make(map[uint]bool, len(fixtureSlot.Banners))
+	for _, fixtureBanner := range fixtureSlot.Banners {
+		if desired[fixtureBanner.ID] {
+			return nil, errors.Errorf("banner %v/%v/%v is duplicated", pageURL, fixtureSlot.ID, fixtureBanner.ID)
+		}
+		desired[fixtureBanner.ID] = true
+	}
+	existing := make(map[uint]entities.Banner, len(bannerTrees))
+	for _, tree := range bannerTrees {
+		existing[tree.InnerID] = tree.Banner
+		// deleted banners are planned first, they don't have to fit the slot.
+		if prune && !desired[tree.InnerID] {
+			plan = append(plan, Change{Action: ChangeDelete, Kind: KindBanner, PageURL: pageURL, Slot: slot, Banner: tree.Banner})
+		}
+	}
+	for _, fixtureBanner := range fixtureSlot.Banners {
+		banner := fixtureBanner.banner()
+		current, ok := existing[banner.InnerID]
+		switch {
+		case !ok:
+			plan = append(plan, Change{Action: ChangeCreate, Kind: KindBanner, PageURL: pageURL, Slot: slot, Banner: banner})
+		case current != banner:
+			plan = append(plan, Change{Action: ChangeUpdate, Kind: KindBanner, PageURL: pageURL, Slot: slot, Banner: banner})
+		}
+	}
+	return plan, nil
+}
+
+// planSlotUpdate orders the slot update with its banner changes, so banners fit the slot at every step.
+// The slot shrinks to the size common with the current one after the banners which fit this size are changed,
+// then it grows to the updated size before the other banners are changed.
+func planSlotUpdate(pageURL string, current, updated entities.Slot, banners []Change) (plan []Change) {
+	common := updated
+	common.Width = commonSize(current.Width, updated.Width)
+	common.Height = commonSize(current.Height, updated.Height)
+	var late []Change
+	for _, change := range banners {
+		if change.Action != ChangeDelete && !common.Fits(change.Banner.Creative) {
+			late = append(late, change)
+			continue
+		}
+		plan = append(plan, change)
+	}
+	shrink := shrinks(current, updated)
+	if shrink {
+		plan = append(plan, Change{Action: ChangeUpdate, Kind: KindSlot, PageURL: pageURL, Slot: common})
+	}
+	if !shrink || common != updated {
+		plan = append(plan, Change{Action: ChangeUpdate, Kind: KindSlot, PageURL: pageURL, Slot: updated})
+	}
+	return append(plan, late...)
+}
+
+// commonSize returns the size which fits both the current and updated slot dimension, zero value means any size.
+func commonSize(current, updated uint) uint {
+	if current == 0 || (updated != 0 && updated < current) {
+		return updated
+	}
+	return current
+}
+
+// shrinks reports whether the updated slot is less than the current one in any dimension.
+func shrinks(current, updated entities.Slot) bool {
+	less := func(current, updated uint) bool {
+		return updated != 0 && (current == 0 || updated < current)
+	}
+	return less(current.Width, updated.Width) || less(current.Height, updated.Height)
+}
+
+// ApplyInventory applies changes of the plan in order and stops on the first failed change.
+func (r *RotatorInteractor) ApplyInventory(ctx context.Context, plan []Change) error {
+	for _, change := range plan {
+		if err := r.applyChange(ctx, change); err != nil {
+			return errors.Wrapf(err, ErrApplyInventory, change)
+		}
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) applyChange(ctx context.Context, c Change) error {
+	switch c.Kind {
+	case KindPage:
+		switch c.Action {
+		case ChangeCreate:
+			return r.AddPage(ctx, c.PageURL, c.Settings)
+		case ChangeUpdate:
+			return r.SetPageSettings(ctx, c.PageURL, c.Settings)
+		case ChangeDelete:
+			return r.DeletePage(ctx, c.PageURL)
+		}
+	case KindSlot:
+		switch c.Action {
+		case ChangeCreate:
+			return r.AddSlot(ctx, c.PageURL, c.Slot.InnerID, c.Slot.Description, c.Slot.ViewableTry, c.Slot.Width, c.Slot.Height)
+		case ChangeUpdate:
+			return r.UpdateSlot(ctx, c.PageURL, c.Slot.InnerID, c.Slot.Description, c.Slot.ViewableTry, c.Slot.Width, c.Slot.Height)
+		case ChangeDelete:
+			return r.DeleteSlot(ctx, c.PageURL, c.Slot.InnerID)
+		}
+	case KindBanner:
+		switch c.Action {
+		case ChangeCreate:
+			return r.AddBannerToSlot(ctx, c.PageURL, c.Slot.InnerID, c.Banner.InnerID, c.Banner.Description, c.Banner.Creative)
+		case ChangeUpdate:
+			return r.UpdateBanner(ctx, c.PageURL, c.Slot.InnerID, c.Banner.InnerID, c.Banner.Description, c.Banner.Creative)
+		case ChangeDelete:
+			return r.DeleteBannerFromSlot(ctx, c.PageURL, c.Slot.InnerID, c.Banner.InnerID)
+		}
+	}
+	return errors.Errorf("unknown change %v of %v", c.Action, c.Kind)
+}
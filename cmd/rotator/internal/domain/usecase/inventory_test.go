@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
+	"github.com/shipa988/banner_rotator/internal/data/repository"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func TestRotatorInteractor_Inventory(t *testing.T) {
+	ctx := context.Background()
+	logger := zaplogger.NewLogger(ioutil.Discard, false)
+	rotator, err := NewRotatorInteractor(repository.NewMemRepo(logger), nil, nopAlgo{}, NewStatsBus(time.Second), logger)
+	require.NoError(t, err)
+	require.NoError(t, rotator.AddSlot(ctx, "old.com", 1, "top", false, 0, 0))
+	require.NoError(t, rotator.AddSlot(ctx, "mysite.com", 1, "top", false, 0, 0))
+	require.NoError(t, rotator.AddSlot(ctx, "mysite.com", 2, "bottom", false, 0, 0))
+	require.NoError(t, rotator.AddBannerToSlot(ctx, "mysite.com", 1, 1, "sale", entities.Creative{Width: 400}))
+	require.NoError(t, rotator.AddBannerToSlot(ctx, "mysite.com", 1, 2, "new", entities.Creative{}))
+
+	inventory := Inventory{Pages: []FixturePage{
+		{URL: "mysite.com", Slots: []FixtureSlot{
+			{ID: 1, Description: "top", Width: 300, Banners: []FixtureBanner{
+				{ID: 1, Description: "sale", Creative: entities.Creative{Width: 300}},
+				{ID: 3, Description: "promo"},
+			}},
+		}},
+		{URL: "other.com", Slots: []FixtureSlot{{ID: 1, Description: "top"}}},
+	}}
+	toStrings := func(plan []Change) (changes []string) {
+		for _, change := range plan {
+			changes = append(changes, change.String())
+		}
+		return changes
+	}
+
+	plan, err := rotator.PlanInventory(ctx, inventory, false)
+	require.NoError(t, err)
+	// banners are updated before the slot becomes narrower.
+	require.Equal(t, []string{
+		"~ banner mysite.com/1/1",
+		"+ banner mysite.com/1/3",
+		"~ slot mysite.com/1",
+		"+ page other.com",
+		"+ slot other.com/1",
+	}, toStrings(plan))
+
+	plan, err = rotator.PlanInventory(ctx, inventory, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"- banner mysite.com/1/2",
+		"~ banner mysite.com/1/1",
+		"+ banner mysite.com/1/3",
+		"~ slot mysite.com/1",
+		"- slot mysite.com/2",
+		"+ page other.com",
+		"+ slot other.com/1",
+		"- page old.com",
+	}, toStrings(plan))
+	require.NoError(t, rotator.ApplyInventory(ctx, plan))
+
+	plan, err = rotator.PlanInventory(ctx, inventory, true)
+	require.NoError(t, err)
+	require.Empty(t, plan)
+	pages, err := rotator.GetPages(ctx)
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	banners, err := rotator.GetBannersBySlotID(ctx, "mysite.com", 1)
+	require.NoError(t, err)
+	require.Len(t, banners, 2)
+
+	inventory.Pages = append(inventory.Pages, inventory.Pages[0])
+	_, err = rotator.PlanInventory(ctx, inventory, false)
+	require.Error(t, err)
+}
+
+func TestRotatorInteractor_InventoryResizeSlot(t *testing.T) {
+	ctx := context.Background()
+	logger := zaplogger.NewLogger(ioutil.Discard, false)
+	rotator, err := NewRotatorInteractor(repository.NewMemRepo(logger), nil, nopAlgo{}, NewStatsBus(time.Second), logger)
+	require.NoError(t, err)
+	require.NoError(t, rotator.AddSlot(ctx, "mysite.com", 1, "top", false, 300, 250))
+	require.NoError(t, rotator.AddBannerToSlot(ctx, "mysite.com", 1, 1, "sale", entities.Creative{Width: 300, Height: 250}))
+
+	// the slot becomes narrower and higher.
+	inventory := Inventory{Pages: []FixturePage{
+		{URL: "mysite.com", Slots: []FixtureSlot{
+			{ID: 1, Description: "side", Width: 200, Height: 600, Banners: []FixtureBanner{
+				{ID: 1, Description: "sale", Creative: entities.Creative{Width: 200, Height: 250}},
+				{ID: 2, Description: "tower", Creative: entities.Creative{Width: 200, Height: 600}},
+			}},
+		}},
+	}}
+	plan, err := rotator.PlanInventory(ctx, inventory, false)
+	require.NoError(t, err)
+	var changes []string
+	var slots []entities.Slot
+	for _, change := range plan {
+		changes = append(changes, change.String())
+		if change.Kind == KindSlot {
+			slots = append(slots, change.Slot)
+		}
+	}
+	require.Equal(t, []string{
+		"~ banner mysite.com/1/1",
+		"~ slot mysite.com/1",
+		"~ slot mysite.com/1",
+		"+ banner mysite.com/1/2",
+	}, changes)
+	require.Equal(t, []entities.Slot{
+		{InnerID: 1, Description: "side", Width: 200, Height: 250},
+		{InnerID: 1, Description: "side", Width: 200, Height: 600},
+	}, slots)
+	require.NoError(t, rotator.ApplyInventory(ctx, plan))
+
+	plan, err = rotator.PlanInventory(ctx, inventory, false)
+	require.NoError(t, err)
+	require.Empty(t, plan)
+}
+
+func TestRotatorInteractor_InventorySharedBanner(t *testing.T) {
+	ctx := context.Background()
+	logger := zaplogger.NewLogger(ioutil.Discard, false)
+	rotator, err := NewRotatorInteractor(repository.NewMemRepo(logger), nil, nopAlgo{}, NewStatsBus(time.Second), logger)
+	require.NoError(t, err)
+	for slotID := uint(1); slotID <= 2; slotID++ {
+		require.NoError(t, rotator.AddSlot(ctx, "mysite.com", slotID, "top", false, 0, 0))
+		require.NoError(t, rotator.AddBannerToSlot(ctx, "mysite.com", slotID, 1, "sale", entities.Creative{}))
+	}
+	slot := func(slotID uint, description string) FixtureSlot {
+		return FixtureSlot{ID: slotID, Description: "top", Banners: []FixtureBanner{{ID: 1, Description: description}}}
+	}
+
+	// the banner is renamed in both slots by one update.
+	inventory := Inventory{Pages: []FixturePage{{URL: "mysite.com", Slots: []FixtureSlot{slot(1, "promo"), slot(2, "sale")}}}}
+	_, err = rotator.PlanInventory(ctx, inventory, false)
+	require.Error(t, err)
+	inventory.Pages[0].Slots = inventory.Pages[0].Slots[:1]
+	_, err = rotator.PlanInventory(ctx, inventory, false)
+	require.Error(t, err)
+	plan, err := rotator.PlanInventory(ctx, inventory, true)
+	require.NoError(t, err)
+	require.Len(t, plan, 2)
+
+	inventory.Pages[0].Slots = []FixtureSlot{slot(1, "promo"), slot(2, "promo")}
+	plan, err = rotator.PlanInventory(ctx, inventory, false)
+	require.NoError(t, err)
+	require.NoError(t, rotator.ApplyInventory(ctx, plan))
+	plan, err = rotator.PlanInventory(ctx, inventory, false)
+	require.NoError(t, err)
+	require.Empty(t, plan)
+}
@@ -1,6 +1,9 @@
 package usecase
 
-import "github.com/shipa988/banner_rotator/internal/domain/entities"
+import (
+	"context"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
 
 type AlgoError struct {
 	Mess        string
@@ -14,14 +17,50 @@ func (a AlgoError) Error() string {
 	return a.Mess
 }
 
+// Unwrap matches errors of the old schema with entities.ErrSchemaOutOfDate, other ones mean there is no banner to show.
+func (a AlgoError) Unwrap() error {
+	if a.IsOldSchema {
+		return entities.ErrSchemaOutOfDate
+	}
+	return entities.ErrNotFound
+}
+
+// notFoundAfterInit returns notFound if the algorithm has no state for the slot or banner after the schema is updated,
+// so they don't exist and only a schema changed during the request is temporary.
+func notFoundAfterInit(err error, notFound error) error {
+	if e, ok := err.(*AlgoError); e != nil && ok && e.Temporary() {
+		return notFound
+	}
+	return err
+}
+
 type GroupStats map[entities.Group]entities.Action
 type Banners map[entities.Banner]GroupStats
 type Slots map[entities.Slot]Banners
 type Pages map[entities.Page]Slots
 
+// BannerInfo is the banner with its actions and algorithm scores by user group.
+type BannerInfo struct {
+	entities.Banner
+	Stats  GroupStats
+	Scores map[string]float64
+}
+
+// ArmFilter reports whether the banner is a candidate to be shown, nil filter allows all banners.
+type ArmFilter func(bannerID uint) bool
+
 type NextBannerAlgo interface {
-	GetNext(pageURL string, slotID uint, groupDescription string) (id uint, err error)
-	UpdateTry(pageURL string, slotID, bannerID uint, groupDescription string) error
-	UpdateReward(pageURL string, slotID, bannerID uint, groupDescription string) error
-	Init(pages *Pages) error
+	GetNext(ctx context.Context, pageURL string, slotID uint, groupDescription string, filter ArmFilter) (id uint, err error)
+	UpdateTry(ctx context.Context, pageURL string, slotID, bannerID uint, groupDescription string) error
+	UpdateReward(ctx context.Context, pageURL string, slotID, bannerID uint, groupDescription string) error
+	Init(ctx context.Context, pages *Pages) error
+	// RemovePage drops the algorithm state of the deleted page.
+	RemovePage(ctx context.Context, pageURL string) error
+	// Scores returns current banner scores in the slot by group description.
+	Scores(ctx context.Context, pageURL string, slotID uint) (scores map[string]map[uint]float64, err error)
+}
+
+// AlgoRegistry is implemented by algorithms which can rotate pages with different named algorithms.
+type AlgoRegistry interface {
+	HasAlgorithm(name string) bool
 }
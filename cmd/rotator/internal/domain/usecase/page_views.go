@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+const defaultPageViewTTL = time.Minute * 30
+
+// pageViews remembers banners served to page views for competitive exclusion.
+type pageViews struct {
+	sync.Mutex
+	ttl       time.Duration
+	views     map[string]*pageView
+	lastSweep time.Time
+}
+
+type pageView struct {
+	sync.Mutex
+	banners map[uint]uint // slot id -> banner id.
+	expires time.Time
+}
+
+func newPageViews(ttl time.Duration) *pageViews {
+	return &pageViews{
+		ttl:       ttl,
+		views:     make(map[string]*pageView),
+		lastSweep: time.Now(),
+	}
+}
+
+// lock returns the locked page view, it is kept locked while the banner of the slot is selected and recorded,
+// so concurrent slots of the page view don't serve competing banners.
+func (pv *pageViews) lock(pageViewID string) *pageView {
+	pv.Lock()
+	now := time.Now()
+	// drop expired page views.
+	if now.Sub(pv.lastSweep) > pv.ttl {
+		for id, view := range pv.views {
+			if view.expires.Before(now) {
+				delete(pv.views, id)
+			}
+		}
+		pv.lastSweep = now
+	}
+	view, ok := pv.views[pageViewID]
+	if !ok || view.expires.Before(now) {
+		view = &pageView{banners: make(map[uint]uint)}
+		pv.views[pageViewID] = view
+	}
+	view.expires = now.Add(pv.ttl)
+	pv.Unlock()
+	view.Lock()
+	return view
+}
+
+// served returns banners served to the page view in slots other than slotID.
+func (v *pageView) served(slotID uint) (banners []uint) {
+	for slot, banner := range v.banners {
+		if slot != slotID {
+			banners = append(banners, banner)
+		}
+	}
+	return
+}
+
+func (v *pageView) add(slotID, bannerID uint) {
+	v.banners[slotID] = bannerID
+}
+
+type bannerTags struct {
+	sync.RWMutex
+	tags map[uint]entities.Tags
+}
+
+func (bt *bannerTags) set(tags map[uint]entities.Tags) {
+	bt.Lock()
+	defer bt.Unlock()
+	bt.tags = tags
+}
+
+// filter returns arm filter which excludes banners competing with served banners.
+func (bt *bannerTags) filter(served []uint) ArmFilter {
+	bt.RLock()
+	defer bt.RUnlock()
+	if len(served) == 0 || len(bt.tags) == 0 {
+		return nil
+	}
+	tags := bt.tags
+	return func(bannerID uint) bool {
+		for _, servedID := range served {
+			if tags[bannerID].Conflicts(tags[servedID]) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// allowed returns arm filter which excludes banners of categories not allowed on the page.
+func (bt *bannerTags) allowed(settings entities.PageSettings) ArmFilter {
+	if len(settings.AllowedCategories) == 0 {
+		return nil
+	}
+	bt.RLock()
+	defer bt.RUnlock()
+	tags := bt.tags
+	return func(bannerID uint) bool {
+		return settings.Allows(tags[bannerID])
+	}
+}
+
+// combineFilters returns filter which allows banners allowed by all filters.
+func combineFilters(filters ...ArmFilter) ArmFilter {
+	fs := make([]ArmFilter, 0, len(filters))
+	for _, f := range filters {
+		if f != nil {
+			fs = append(fs, f)
+		}
+	}
+	if len(fs) == 0 {
+		return nil
+	}
+	return func(bannerID uint) bool {
+		for _, f := range fs {
+			if !f(bannerID) {
+				return false
+			}
+		}
+		return true
+	}
+}
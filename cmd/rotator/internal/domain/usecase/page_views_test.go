@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
+	"github.com/shipa988/banner_rotator/internal/data/repository"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+// preferAlgo returns the preferred banner of the slot if the filter allows it, otherwise the fallback banner.
+type preferAlgo struct {
+	nopAlgo
+	preferred map[uint]uint
+	fallback  uint
+}
+
+func (a preferAlgo) GetNext(ctx context.Context, pageURL string, slotID uint, groupDescription string, filter ArmFilter) (uint, error) {
+	banner := a.preferred[slotID]
+	// let the other slot select its banner.
+	time.Sleep(time.Millisecond)
+	if filter != nil && !filter(banner) {
+		return a.fallback, nil
+	}
+	return banner, nil
+}
+
+type nopQueue struct{}
+
+func (nopQueue) Pull(context.Context, chan<- entities.QueuedEvent) error { return nil }
+func (nopQueue) Commit(context.Context, []entities.QueuedEvent) error    { return nil }
+func (nopQueue) Push(context.Context, entities.Event) error              { return nil }
+
+func TestRotatorInteractor_GetNextBannerConcurrentSlots(t *testing.T) {
+	ctx := context.Background()
+	logger := zaplogger.NewLogger(ioutil.Discard, false)
+	algo := preferAlgo{preferred: map[uint]uint{1: 1, 2: 2}, fallback: 3}
+	rotator, err := NewRotatorInteractor(repository.NewMemRepo(logger), nopQueue{}, algo, NewStatsBus(time.Second), logger)
+	require.NoError(t, err)
+	tags := map[uint]entities.Tags{
+		1: {Categories: []string{"cola"}, Competitors: []string{"pepsi"}},
+		2: {Categories: []string{"pepsi"}, Competitors: []string{"cola"}},
+		3: {Categories: []string{"water"}},
+	}
+	rotator.tags.set(tags)
+
+	for i := 0; i < 20; i++ {
+		pageViewID := fmt.Sprintf("view-%v", i)
+		banners := make([]uint, 2)
+		var wg sync.WaitGroup
+		for slot := uint(1); slot <= 2; slot++ {
+			wg.Add(1)
+			go func(slot uint) {
+				defer wg.Done()
+				banner, err := rotator.GetNextBanner(ctx, "site.com", slot, 30, "man", nil, pageViewID)
+				require.NoError(t, err)
+				banners[slot-1] = banner
+			}(slot)
+		}
+		wg.Wait()
+		require.False(t, tags[banners[0]].Conflicts(tags[banners[1]]), "page view %v got competing banners %v", pageViewID, banners)
+	}
+}
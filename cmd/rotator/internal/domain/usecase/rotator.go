@@ -1,21 +1,89 @@
 package usecase
 
-import "github.com/shipa988/banner_rotator/internal/domain/entities"
+import (
+	"context"
+	"time"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
 
 type Rotator interface {
-	AddSlot(pageURL string, slotID uint, slotDescription string) error
-	DeleteSlot(pageURL string, slotID uint) error
-	DeleteAllSlots(pageURL string) error
-	GetSlotsByPageURL(pageURL string) (slots []entities.Slot, err error)
+	AddPage(ctx context.Context, pageURL string, settings entities.PageSettings) error
+	RenamePage(ctx context.Context, pageURL, newPageURL string) error
+	SetPageSettings(ctx context.Context, pageURL string, settings entities.PageSettings) error
+	GetPages(ctx context.Context) (pages map[string]entities.PageSettings, err error)
+	DeletePage(ctx context.Context, pageURL string) error
+
+	AddSlot(ctx context.Context, pageURL string, slotID uint, slotDescription string, viewableTry bool, width, height uint) error
+	DeleteSlot(ctx context.Context, pageURL string, slotID uint) error
+	DeleteAllSlots(ctx context.Context, pageURL string) error
+	UpdateSlot(ctx context.Context, pageURL string, slotID uint, slotDescription string, viewableTry bool, width, height uint) error
+	GetSlotsByPageURL(ctx context.Context, pageURL string) (slots []entities.Slot, err error)
+
+	AddBannerToSlot(ctx context.Context, pageURL string, slotID uint, bannerID uint, bannerDescription string, creative entities.Creative) error
+	DeleteBannerFromSlot(ctx context.Context, pageURL string, slotID, bannerID uint) error
+	DeleteAllBannersFormSlot(ctx context.Context, pageURL string, slotID uint) error
+	// UpdateBanner changes the banner in every slot it is attached to, the creative should fit all of them.
+	UpdateBanner(ctx context.Context, pageURL string, slotID, bannerID uint, bannerDescription string, creative entities.Creative) error
+	GetBannersBySlotID(ctx context.Context, pageURL string, slotID uint) (banners []entities.Banner, err error)
+	GetBanner(ctx context.Context, pageURL string, slotID, bannerID uint) (banner *entities.Banner, err error)
+	GetBannerInfos(ctx context.Context, pageURL string, slotID uint) (banners []BannerInfo, err error)
+	GetBannerInfo(ctx context.Context, pageURL string, slotID, bannerID uint) (banner *BannerInfo, err error)
+
+	AddCatalogBanner(ctx context.Context, bannerID uint, bannerDescription string, creative entities.Creative) error
+	GetCatalogBanners(ctx context.Context) (banners []entities.Banner, err error)
+	// DeleteCatalogBanner deletes the catalog banner detached from all slots.
+	DeleteCatalogBanner(ctx context.Context, bannerID uint) error
+	AttachBanner(ctx context.Context, pageURL string, slotID, bannerID uint) error
+	DetachBanner(ctx context.Context, pageURL string, slotID, bannerID uint) error
+	GetBannerStat(ctx context.Context, bannerID uint) (GroupStats, error)
+
+	ClickByBanner(ctx context.Context, pageURL string, slotID, bannerID, userAge uint, userSex string, attributes map[string]string) error
+	ViewBanner(ctx context.Context, pageURL string, slotID, bannerID, userAge uint, userSex string, attributes map[string]string) error
+	GetNextBanner(ctx context.Context, pageURL string, slotID, userAge uint, userSex string, attributes map[string]string, pageViewID string) (bannerID uint, err error)
+	Init(ctx context.Context) error
+	// Seed creates the groups, pages, slots and banners of the fixture and updates the ones which differ from it.
+	Seed(ctx context.Context, fixture Fixture) (report SeedReport, err error)
+	// PlanInventory returns changes which make pages, slots and banners equal to the inventory,
+	// objects which are absent in the inventory are deleted only with prune.
+	PlanInventory(ctx context.Context, inventory Inventory, prune bool) (plan []Change, err error)
+	ApplyInventory(ctx context.Context, plan []Change) error
+
+	GetPageStat(ctx context.Context, pageURL string) (Slots, error)
+	// SubscribeOnStats returns page stats and their deltas pushed until ctx is done,
+	// the stats are approximate because the deltas aren't synchronized with the written stats.
+	SubscribeOnStats(ctx context.Context, pageURL string) (snapshot Slots, deltas <-chan StatDeltas, err error)
+	// GetStats returns page stats of the filter summed by the groupBy dimensions.
+	GetStats(ctx context.Context, pageURL string, filter entities.StatFilter, groupBy []entities.StatDimension) (rows []entities.StatRow, err error)
+	// GetExportStats returns stats of the page by slot, banner and group, empty page url means all pages.
+	GetExportStats(ctx context.Context, pageURL string, from, to time.Time) (rows []entities.PageStatRow, err error)
+	// GetStatHistory returns statistics buckets of the page in [from, to), zero slot or banner id means any.
+	GetStatHistory(ctx context.Context, pageURL string, slotID, bannerID uint, from, to time.Time, granularity entities.Granularity) (buckets []entities.StatBucket, err error)
+	// GetSignificance returns CTR confidence intervals and probabilities to be best of banners by slot and group,
+	// zero slot id means all slots, zero confidence means the default one.
+	GetSignificance(ctx context.Context, pageURL string, slotID uint, confidence float64) (rows []entities.BannerSignificance, err error)
+
+	AddAdvertiser(ctx context.Context, advertiserName string) error
+	AddCampaign(ctx context.Context, advertiserName, campaignName string) error
+	AddBannerToCampaign(ctx context.Context, campaignName string, bannerID uint) error
+	PauseCampaign(ctx context.Context, campaignName string) error
+	ResumeCampaign(ctx context.Context, campaignName string) error
+	PauseAdvertiser(ctx context.Context, advertiserName string) error
+	ResumeAdvertiser(ctx context.Context, advertiserName string) error
+	GetCampaignStat(ctx context.Context, campaignName string) (GroupStats, error)
+	GetAdvertiserStat(ctx context.Context, advertiserName string) (GroupStats, error)
 
-	AddBannerToSlot(pageURL string, slotID uint, bannerID uint, bannerDescription string) error
-	DeleteBannerFromSlot(pageURL string, slotID, bannerID uint) error
-	DeleteAllBannersFormSlot(pageURL string, slotID uint) error
-	GetBannersBySlotID(pageURL string, slotID uint) (banners []entities.Banner, err error)
+	AddGroup(ctx context.Context, group entities.Group, rules []entities.Rule) error
+	UpdateGroup(ctx context.Context, groupDescription string, group entities.Group, rules []entities.Rule) error
+	// DeleteGroup deletes the group which has no events.
+	DeleteGroup(ctx context.Context, groupDescription string) error
+	GetGroups(ctx context.Context) (groups []entities.Group, defaultGroupDescription string, err error)
+	GetGroupRules(ctx context.Context) (rules map[string][]entities.Rule, err error)
 
-	ClickByBanner(pageURL string, slotID, bannerID, userAge uint, userSex string) error
-	GetNextBanner(pageURL string, slotID, userAge uint, userSex string) (bannerID uint, err error)
-	Init() error
+	SetBannerTargeting(ctx context.Context, bannerID uint, targeting entities.Targeting) error
+	DeleteBannerTargeting(ctx context.Context, bannerID uint) error
+	GetBannerTargeting(ctx context.Context, bannerID uint) (targeting entities.Targeting, err error)
 
-	GetPageStat(pageURL string) (Slots, error)
+	SetBannerTags(ctx context.Context, bannerID uint, tags entities.Tags) error
+	GetBannerTags(ctx context.Context, bannerID uint) (tags entities.Tags, err error)
 }
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -13,265 +15,1127 @@ import (
 var _ Rotator = (*RotatorInteractor)(nil)
 
 const (
+	ErrAddPage            = "can't add new page: %v"
+	ErrRenamePage         = "can't rename page: %v to: %v"
+	ErrSetPageSettings    = "can't set settings for page: %v"
+	ErrGetPages           = "can't return pages"
+	ErrDeletePage         = "can't delete page: %v"
+	ErrInitPageSettings   = "can't init page settings"
 	ErrAddSlot            = "can't add new slot id: %v, description: %v for page: %v"
 	ErrDeleteSlot         = "can't delete slot id: %v for page: %v"
 	ErrDeleteSlots        = "can't delete slots for page: %v"
+	ErrUpdateSlot         = "can't update slot id: %v for page: %v"
+	ErrUpdateBanner       = "can't update banner id: %v for page: %v, slot id: %v"
 	ErrAddBanner          = "can't add new banner id: %v, description: %v for page: %v, slot id: %v"
 	ErrDeleteBanner       = "can't delete banner id: %v for page: %v, slot id: %v"
 	ErrDeleteBanners      = "can't delete banners for page: %v, slot id: %v"
+	ErrAddCatalogBanner   = "can't add banner id: %v to catalog"
+	ErrGetCatalogBanners  = "can't return catalog banners"
+	ErrRemoveFromCatalog  = "can't delete banner id: %v from catalog"
+	ErrAttachBanner       = "can't attach banner id: %v to page: %v, slot id: %v"
+	ErrDetachBanner       = "can't detach banner id: %v from page: %v, slot id: %v"
+	ErrGetBannerStat      = "can't return stat for banner id: %v"
 	ErrClickOnBanner      = "can't register click event for banner id: %v page: %v, slot id: %v"
+	ErrViewBanner         = "can't register view event for banner id: %v page: %v, slot id: %v"
 	ErrGetBanners         = "can't return banners for page: %v, slot id: %v"
+	ErrGetBanner          = "can't return banner id: %v for page: %v, slot id: %v"
 	ErrGetSlots           = "can't return slots for page: %v"
 	ErrGetNextBanner      = "can't return next banner for page: %v, slot id: %v"
 	ErrGetPageStat        = "can't return click stat for page: %v"
+	ErrGetStatHistory     = "can't return stat history for page: %v"
+	ErrGetStats           = "can't return stats for page: %v"
+	ErrGetExportStats     = "can't return stats for export"
+	ErrGetSignificance    = "can't return significance for page: %v"
 	ErrInitNextBannerAlgo = "can't init banner rotate algorithm when extract %v"
+	ErrAddAdvertiser      = "can't add new advertiser: %v"
+	ErrAddCampaign        = "can't add new campaign: %v for advertiser: %v"
+	ErrAddCampaignBanner  = "can't add banner id: %v to campaign: %v"
+	ErrPauseCampaign      = "can't change pause state of campaign: %v"
+	ErrPauseAdvertiser    = "can't change pause state of advertiser: %v"
+	ErrGetCampaignStat    = "can't return stat for campaign: %v"
+	ErrGetAdvertiserStat  = "can't return stat for advertiser: %v"
+	ErrAddGroup           = "can't add new group: %v"
+	ErrUpdateGroup        = "can't update group: %v"
+	ErrDeleteGroup        = "can't delete group: %v"
+	ErrGetGroups          = "can't return groups"
+	ErrSetTargeting       = "can't set targeting for banner id: %v"
+	ErrDeleteTargeting    = "can't delete targeting for banner id: %v"
+	ErrGetTargeting       = "can't return targeting for banner id: %v"
+	ErrInitTargetings     = "can't init banner targetings"
+	ErrSetTags            = "can't set tags for banner id: %v"
+	ErrGetTags            = "can't return tags for banner id: %v"
+	ErrInitTags           = "can't init banner tags"
 )
 
 type RotatorInteractor struct {
 	pageRepo       entities.PageRepository
 	slotRepo       entities.SlotRepository
 	bannerRepo     entities.BannerRepository
+	catalogRepo    entities.BannerCatalogRepository
 	groupRepo      entities.GroupRepository
 	actionRepo     entities.ActionRepository
+	advertiserRepo entities.AdvertiserRepository
+	campaignRepo   entities.CampaignRepository
+	targetingRepo  entities.TargetingRepository
+	tagsRepo       entities.TagsRepository
+	historyRepo    entities.HistoryRepository
+	treeRepo       entities.PageTreeRepository
 	eventQueue     entities.EventQueue
+	statsBus       *StatsBus
 	nextBannerAlgo NextBannerAlgo
 	userGroups     userGroups
+	viewableSlots  viewableSlots
+	targetings     bannerTargetings
+	tags           bannerTags
+	pageViews      *pageViews
+	pageSettings   pageSettings
 	logger         logger.Logger
 }
 
-func NewRotatorInteractor(repo interface{}, queueManager entities.EventQueue, alg NextBannerAlgo, logger logger.Logger) (*RotatorInteractor, error) {
+func NewRotatorInteractor(repo interface{}, queueManager entities.EventQueue, alg NextBannerAlgo, statsBus *StatsBus, logger logger.Logger) (*RotatorInteractor, error) {
 	rp, pok := repo.(entities.PageRepository)
 	rs, sok := repo.(entities.SlotRepository)
 	rb, bok := repo.(entities.BannerRepository)
 	re, eok := repo.(entities.ActionRepository)
 	rg, gok := repo.(entities.GroupRepository)
+	ra, aok := repo.(entities.AdvertiserRepository)
+	rc, cok := repo.(entities.CampaignRepository)
+	rt, tok := repo.(entities.TargetingRepository)
+	rtg, tgok := repo.(entities.TagsRepository)
+	rbc, bcok := repo.(entities.BannerCatalogRepository)
+	rh, hok := repo.(entities.HistoryRepository)
+	rtr, trok := repo.(entities.PageTreeRepository)
 
-	if !gok || !eok || !sok || !bok || !pok {
-		return nil, errors.New("scheme repository should implements entities.GroupRepository,entities.GroupRepository,entities.SlotRepository,entities.BannerRepository,entities.PageRepository")
+	if !gok || !eok || !sok || !bok || !pok || !aok || !cok || !tok || !tgok || !bcok || !hok || !trok {
+		return nil, errors.New("scheme repository should implements entities.GroupRepository,entities.ActionRepository,entities.SlotRepository,entities.BannerRepository,entities.PageRepository,entities.AdvertiserRepository,entities.CampaignRepository,entities.TargetingRepository,entities.TagsRepository,entities.BannerCatalogRepository,entities.HistoryRepository,entities.PageTreeRepository")
 	}
 
 	return &RotatorInteractor{
 		pageRepo:       rp,
 		slotRepo:       rs,
 		bannerRepo:     rb,
+		catalogRepo:    rbc,
 		actionRepo:     re,
 		groupRepo:      rg,
+		advertiserRepo: ra,
+		campaignRepo:   rc,
+		targetingRepo:  rt,
+		tagsRepo:       rtg,
+		historyRepo:    rh,
+		treeRepo:       rtr,
+		pageViews:      newPageViews(defaultPageViewTTL),
 		nextBannerAlgo: alg,
 		eventQueue:     queueManager,
+		statsBus:       statsBus,
 		logger:         logger,
 	}, nil
 }
 
-func (r *RotatorInteractor) Init() error {
-	if err := r.initNextBannerAlgo(); err != nil {
+func (r *RotatorInteractor) Init(ctx context.Context) error {
+	if err := r.initNextBannerAlgo(ctx); err != nil {
 		return err
 	}
-	if err := r.initUserGroups(); err != nil {
+	if err := r.initUserGroups(ctx); err != nil {
+		return err
+	}
+	if err := r.initTargetings(ctx); err != nil {
+		return err
+	}
+	if err := r.initTags(ctx); err != nil {
+		return err
+	}
+	if err := r.initPageSettings(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *RotatorInteractor) initNextBannerAlgo() error {
+func (r *RotatorInteractor) initNextBannerAlgo(ctx context.Context) error {
 	ps := Pages{}
 
-	pages, err := r.pageRepo.GetPages()
+	trees, err := r.treeRepo.GetPageTrees(ctx, "")
 	if err != nil {
 		return errors.Wrapf(err, ErrInitNextBannerAlgo, "pages")
 	}
+	pausedBanners, err := r.campaignRepo.GetPausedBanners(ctx)
+	if err != nil {
+		return errors.Wrapf(err, ErrInitNextBannerAlgo, "paused banners")
+	}
+	// banners are identified by inner id and description.
+	paused := make(map[entities.Banner]bool, len(pausedBanners))
+	for _, banner := range pausedBanners {
+		paused[entities.Banner{InnerID: banner.InnerID, Description: banner.Description}] = true
+	}
 
-	for _, page := range pages {
-		sl, err := r.GetPageStat(page.URL)
-		if err != nil {
-			return errors.Wrap(err, ErrInitNextBannerAlgo)
+	for _, tree := range trees {
+		sl := toSlots(tree)
+		// banners of paused campaigns don't take part in rotation.
+		for _, banners := range sl {
+			for banner := range banners {
+				if paused[entities.Banner{InnerID: banner.InnerID, Description: banner.Description}] {
+					delete(banners, banner)
+				}
+			}
 		}
-		ps[page] = sl
+		ps[tree.Page] = sl
 	}
-	err = r.nextBannerAlgo.Init(&ps)
+	err = r.nextBannerAlgo.Init(ctx, &ps)
 	if err != nil {
 		return errors.Wrap(err, ErrInitNextBannerAlgo)
 	}
+	r.viewableSlots.init(ps)
 	return nil
 }
 
-func (r *RotatorInteractor) GetPageStat(pageURL string) (Slots, error) {
-	sl := Slots{}
-	// get slots.
-	slots, err := r.slotRepo.GetSlotsByPageURL(pageURL)
+func (r *RotatorInteractor) AddPage(ctx context.Context, pageURL string, settings entities.PageSettings) error {
+	if err := r.validatePageSettings(settings); err != nil {
+		return errors.Wrapf(err, ErrAddPage, pageURL)
+	}
+	if err := r.pageRepo.AddPage(ctx, pageURL, settings); err != nil {
+		return errors.Wrapf(err, ErrAddPage, pageURL)
+	}
+	if err := r.initPageSettings(ctx); err != nil {
+		return errors.Wrapf(err, ErrAddPage, pageURL)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) RenamePage(ctx context.Context, pageURL, newPageURL string) error {
+	if err := r.pageRepo.RenamePage(ctx, pageURL, newPageURL); err != nil {
+		return errors.Wrapf(err, ErrRenamePage, pageURL, newPageURL)
+	}
+	// algorithm state is kept by page url.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrRenamePage, pageURL, newPageURL)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) SetPageSettings(ctx context.Context, pageURL string, settings entities.PageSettings) error {
+	if err := r.validatePageSettings(settings); err != nil {
+		return errors.Wrapf(err, ErrSetPageSettings, pageURL)
+	}
+	if err := r.pageRepo.SetPageSettings(ctx, pageURL, settings); err != nil {
+		return errors.Wrapf(err, ErrSetPageSettings, pageURL)
+	}
+	// page can be moved to other algorithm.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrSetPageSettings, pageURL)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) GetPages(ctx context.Context) (pages map[string]entities.PageSettings, err error) {
+	if pages, err = r.pageRepo.GetPageSettings(ctx); err != nil {
+		return nil, errors.Wrap(err, ErrGetPages)
+	}
+	return pages, nil
+}
+
+func (r *RotatorInteractor) DeletePage(ctx context.Context, pageURL string) error {
+	if err := r.pageRepo.DeletePage(ctx, pageURL); err != nil {
+		return errors.Wrapf(err, ErrDeletePage, pageURL)
+	}
+	if err := r.nextBannerAlgo.RemovePage(ctx, pageURL); err != nil {
+		return errors.Wrapf(err, ErrDeletePage, pageURL)
+	}
+	if err := r.initPageSettings(ctx); err != nil {
+		return errors.Wrapf(err, ErrDeletePage, pageURL)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) validatePageSettings(settings entities.PageSettings) error {
+	if settings.Algorithm == "" {
+		return nil
+	}
+	if registry, ok := r.nextBannerAlgo.(AlgoRegistry); !ok || !registry.HasAlgorithm(settings.Algorithm) {
+		return entities.ErrUnknownAlgorithm(settings.Algorithm)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) initPageSettings(ctx context.Context) error {
+	settings, err := r.pageRepo.GetPageSettings(ctx)
+	if err != nil {
+		return errors.Wrap(err, ErrInitPageSettings)
+	}
+	r.pageSettings.set(settings)
+	return nil
+}
+
+func (r *RotatorInteractor) GetPageStat(ctx context.Context, pageURL string) (Slots, error) {
+	trees, err := r.treeRepo.GetPageTrees(ctx, pageURL)
 	if err != nil {
-		return nil, errors.Wrapf(err, ErrGetPageStat, "slots")
-	}
-	// scan slots.
-	for _, slot := range slots {
-		bn := Banners{}
-		sl[slot] = bn
-		// get banners.
-		banners, err := r.bannerRepo.GetBannersBySlotID(pageURL, slot.InnerID)
+		return nil, errors.Wrapf(err, ErrGetPageStat, pageURL)
+	}
+	sl := Slots{}
+	for _, tree := range trees {
+		sl = toSlots(tree)
+	}
+	return sl, nil
+}
+
+// toSlots converts the page tree to banner stats by slot.
+func toSlots(tree entities.PageTree) Slots {
+	sl := make(Slots, len(tree.Slots))
+	for _, slot := range tree.Slots {
+		bn := make(Banners, len(slot.Banners))
+		for _, banner := range slot.Banners {
+			bn[banner.Banner] = banner.Actions
+		}
+		sl[slot.Slot] = bn
+	}
+	return sl
+}
+
+// SubscribeOnStats returns the snapshot written by the aggregator and deltas of events registered by this rotator.
+// The snapshot lags behind the queue, so events which aren't written yet are missed
+// and events of deltas can be already counted by the snapshot, the stream is approximate.
+func (r *RotatorInteractor) SubscribeOnStats(ctx context.Context, pageURL string) (snapshot Slots, deltas <-chan StatDeltas, err error) {
+	deltas = r.statsBus.Subscribe(ctx, pageURL)
+	if snapshot, err = r.GetPageStat(ctx, pageURL); err != nil {
+		return nil, nil, errors.Wrapf(err, ErrGetPageStat, pageURL)
+	}
+	return snapshot, deltas, nil
+}
+
+func (r *RotatorInteractor) GetStats(ctx context.Context, pageURL string, filter entities.StatFilter, groupBy []entities.StatDimension) (rows []entities.StatRow, err error) {
+	var buckets []entities.StatBucket
+	if filter.From.IsZero() && filter.To.IsZero() {
+		// lifetime stats.
+		slots, err := r.GetPageStat(ctx, pageURL)
 		if err != nil {
-			return nil, errors.Wrapf(err, ErrGetPageStat, "banners")
+			return nil, errors.Wrapf(err, ErrGetStats, pageURL)
+		}
+		for slot, banners := range slots {
+			for banner, stats := range banners {
+				for group, action := range stats {
+					buckets = append(buckets, entities.StatBucket{
+						SlotID:           slot.InnerID,
+						BannerID:         banner.InnerID,
+						GroupDescription: group.Description,
+						Action:           action,
+					})
+				}
+			}
+		}
+	} else {
+		to := filter.To
+		if to.IsZero() {
+			to = time.Now()
+		}
+		if buckets, err = r.historyRepo.GetBuckets(ctx, pageURL, filter.SlotID, filter.BannerID, filter.From, to); err != nil {
+			return nil, errors.Wrapf(err, ErrGetStats, pageURL)
+		}
+	}
+	if rows, err = entities.AggregateStats(buckets, filter, groupBy); err != nil {
+		return nil, errors.Wrapf(err, ErrGetStats, pageURL)
+	}
+	return rows, nil
+}
+
+func (r *RotatorInteractor) GetSignificance(ctx context.Context, pageURL string, slotID uint, confidence float64) (rows []entities.BannerSignificance, err error) {
+	if confidence == 0 {
+		confidence = entities.DefaultConfidence
+	}
+	slots, err := r.GetPageStat(ctx, pageURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, ErrGetSignificance, pageURL)
+	}
+	for slot, banners := range slots {
+		if slotID != 0 && slot.InnerID != slotID {
+			continue
+		}
+		// banners compete within the user group.
+		tries := map[string]map[uint]uint{}
+		clicks := map[string]map[uint]uint{}
+		for banner, stats := range banners {
+			for group, action := range stats {
+				if tries[group.Description] == nil {
+					tries[group.Description] = map[uint]uint{}
+					clicks[group.Description] = map[uint]uint{}
+				}
+				tries[group.Description][banner.InnerID] = slot.Tries(action)
+				clicks[group.Description][banner.InnerID] = action.Clicks
+			}
 		}
-		// scan banners.
-		for _, banner := range banners {
-			// get events for each group.
-			events, err := r.actionRepo.GetActions(pageURL, slot.InnerID, banner.InnerID)
+		for group := range tries {
+			significance, err := entities.Significance(slot.InnerID, group, tries[group], clicks[group], confidence)
 			if err != nil {
-				return nil, errors.Wrapf(err, ErrGetPageStat, "events")
+				return nil, errors.Wrapf(err, ErrGetSignificance, pageURL)
 			}
-			bn[banner] = events
+			rows = append(rows, significance...)
 		}
 	}
-	return sl, nil
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].SlotID != rows[j].SlotID {
+			return rows[i].SlotID < rows[j].SlotID
+		}
+		if rows[i].GroupDescription != rows[j].GroupDescription {
+			return rows[i].GroupDescription < rows[j].GroupDescription
+		}
+		return rows[i].BannerID < rows[j].BannerID
+	})
+	return rows, nil
+}
+
+func (r *RotatorInteractor) GetExportStats(ctx context.Context, pageURL string, from, to time.Time) (rows []entities.PageStatRow, err error) {
+	pageURLs := []string{pageURL}
+	if pageURL == "" {
+		pages, err := r.pageRepo.GetPages(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrGetExportStats)
+		}
+		pageURLs = pageURLs[:0]
+		for _, page := range pages {
+			pageURLs = append(pageURLs, page.URL)
+		}
+	}
+	filter := entities.StatFilter{From: from, To: to}
+	groupBy := []entities.StatDimension{entities.StatBySlot, entities.StatByBanner, entities.StatByGroup}
+	for _, url := range pageURLs {
+		stats, err := r.GetStats(ctx, url, filter, groupBy)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrGetExportStats)
+		}
+		for _, stat := range stats {
+			rows = append(rows, entities.PageStatRow{PageURL: url, StatRow: stat})
+		}
+	}
+	return rows, nil
 }
 
-func (r *RotatorInteractor) GetBannersBySlotID(pageURL string, slotID uint) (banners []entities.Banner, err error) {
-	if banners, err = r.bannerRepo.GetBannersBySlotID(pageURL, slotID); err != nil {
+func (r *RotatorInteractor) GetStatHistory(ctx context.Context, pageURL string, slotID, bannerID uint, from, to time.Time, granularity entities.Granularity) (buckets []entities.StatBucket, err error) {
+	size, ok := granularity.Duration()
+	if !ok {
+		return nil, errors.Wrapf(entities.ErrInvalidGranularity(string(granularity)), ErrGetStatHistory, pageURL)
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if buckets, err = r.historyRepo.GetBuckets(ctx, pageURL, slotID, bannerID, from, to); err != nil {
+		return nil, errors.Wrapf(err, ErrGetStatHistory, pageURL)
+	}
+	return entities.RollUpBuckets(buckets, size), nil
+}
+
+func (r *RotatorInteractor) GetBannersBySlotID(ctx context.Context, pageURL string, slotID uint) (banners []entities.Banner, err error) {
+	if banners, err = r.bannerRepo.GetBannersBySlotID(ctx, pageURL, slotID); err != nil {
+		return nil, errors.Wrapf(err, ErrGetBanners, pageURL, slotID)
+	}
+	return banners, nil
+}
+
+func (r *RotatorInteractor) GetBanner(ctx context.Context, pageURL string, slotID, bannerID uint) (banner *entities.Banner, err error) {
+	if banner, err = r.bannerRepo.GetBanner(ctx, pageURL, slotID, bannerID); err != nil {
+		return nil, errors.Wrapf(err, ErrGetBanner, bannerID, pageURL, slotID)
+	}
+	return banner, nil
+}
+
+func (r *RotatorInteractor) GetBannerInfos(ctx context.Context, pageURL string, slotID uint) (banners []BannerInfo, err error) {
+	slot, err := r.slotTree(ctx, pageURL, slotID)
+	if err != nil {
+		return nil, errors.Wrapf(err, ErrGetBanners, pageURL, slotID)
+	}
+	scores, err := r.slotScores(ctx, pageURL, slotID)
+	if err != nil {
 		return nil, errors.Wrapf(err, ErrGetBanners, pageURL, slotID)
 	}
+	for _, banner := range slot.Banners {
+		banners = append(banners, *bannerInfo(banner.Banner, banner.Actions, scores))
+	}
 	return banners, nil
 }
 
-func (r *RotatorInteractor) GetSlotsByPageURL(pageURL string) (slots []entities.Slot, err error) {
-	if slots, err = r.slotRepo.GetSlotsByPageURL(pageURL); err != nil {
+// slotTree returns the slot with banners and their actions loaded at once.
+func (r *RotatorInteractor) slotTree(ctx context.Context, pageURL string, slotID uint) (*entities.SlotTree, error) {
+	// empty page url means all pages for the tree repository.
+	if pageURL == "" || slotID == 0 {
+		return nil, entities.ErrZeroValue()
+	}
+	trees, err := r.treeRepo.GetPageTrees(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	for _, tree := range trees {
+		for i := range tree.Slots {
+			if tree.Slots[i].InnerID == slotID {
+				return &tree.Slots[i], nil
+			}
+		}
+	}
+	return nil, entities.ErrSlotNotFound(slotID, pageURL)
+}
+
+func (r *RotatorInteractor) GetBannerInfo(ctx context.Context, pageURL string, slotID, bannerID uint) (banner *BannerInfo, err error) {
+	b, err := r.bannerRepo.GetBanner(ctx, pageURL, slotID, bannerID)
+	if err != nil {
+		return nil, errors.Wrapf(err, ErrGetBanner, bannerID, pageURL, slotID)
+	}
+	stats, err := r.actionRepo.GetActions(ctx, pageURL, slotID, bannerID)
+	if err != nil {
+		return nil, errors.Wrapf(err, ErrGetBanner, bannerID, pageURL, slotID)
+	}
+	scores, err := r.slotScores(ctx, pageURL, slotID)
+	if err != nil {
+		return nil, errors.Wrapf(err, ErrGetBanner, bannerID, pageURL, slotID)
+	}
+	return bannerInfo(*b, stats, scores), nil
+}
+
+// slotScores returns algorithm scores of the slot, slots unknown to algorithm have no scores.
+func (r *RotatorInteractor) slotScores(ctx context.Context, pageURL string, slotID uint) (map[string]map[uint]float64, error) {
+	scores, err := r.nextBannerAlgo.Scores(ctx, pageURL, slotID)
+	if e, ok := err.(*AlgoError); e != nil && ok && e.Temporary() {
+		return nil, nil
+	}
+	return scores, err
+}
+
+func bannerInfo(banner entities.Banner, stats GroupStats, scores map[string]map[uint]float64) *BannerInfo {
+	info := &BannerInfo{
+		Banner: banner,
+		Stats:  stats,
+		Scores: make(map[string]float64),
+	}
+	for group, bannerScores := range scores {
+		if score, ok := bannerScores[banner.InnerID]; ok {
+			info.Scores[group] = score
+		}
+	}
+	return info
+}
+
+func (r *RotatorInteractor) GetSlotsByPageURL(ctx context.Context, pageURL string) (slots []entities.Slot, err error) {
+	if slots, err = r.slotRepo.GetSlotsByPageURL(ctx, pageURL); err != nil {
 		return nil, errors.Wrapf(err, ErrGetSlots, pageURL)
 	}
 	return slots, nil
 }
 
-func (r *RotatorInteractor) AddSlot(pageURL string, slotID uint, slotDescription string) error {
-	if err := r.slotRepo.AddSlot(pageURL, slotID, slotDescription); err != nil {
+func (r *RotatorInteractor) AddSlot(ctx context.Context, pageURL string, slotID uint, slotDescription string, viewableTry bool, width, height uint) error {
+	if err := r.slotRepo.AddSlot(ctx, pageURL, slotID, slotDescription, viewableTry, width, height); err != nil {
 		return errors.Wrapf(err, ErrAddSlot, slotID, slotDescription, pageURL)
 	}
 	return nil
 }
 
-func (r *RotatorInteractor) DeleteSlot(pageURL string, slotID uint) error {
-	if err := r.slotRepo.DeleteSlot(pageURL, slotID); err != nil {
+func (r *RotatorInteractor) DeleteSlot(ctx context.Context, pageURL string, slotID uint) error {
+	if err := r.slotRepo.DeleteSlot(ctx, pageURL, slotID); err != nil {
 		return errors.Wrapf(err, ErrDeleteSlot, slotID, pageURL)
 	}
 	return nil
 }
 
-func (r *RotatorInteractor) DeleteAllSlots(pageURL string) error {
-	if err := r.slotRepo.DeleteAllSlots(pageURL); err != nil {
+func (r *RotatorInteractor) DeleteAllSlots(ctx context.Context, pageURL string) error {
+	if err := r.slotRepo.DeleteAllSlots(ctx, pageURL); err != nil {
 		return errors.Wrapf(err, ErrDeleteSlots, pageURL)
 	}
 	return nil
 }
 
-func (r *RotatorInteractor) AddBannerToSlot(pageURL string, slotID uint, bannerID uint, bannerDescription string) error {
-	if err := r.bannerRepo.AddBannerToSlot(pageURL, slotID, bannerID, bannerDescription); err != nil {
+func (r *RotatorInteractor) UpdateSlot(ctx context.Context, pageURL string, slotID uint, slotDescription string, viewableTry bool, width, height uint) error {
+	banners, err := r.bannerRepo.GetBannersBySlotID(ctx, pageURL, slotID)
+	if err != nil {
+		return errors.Wrapf(err, ErrUpdateSlot, slotID, pageURL)
+	}
+	// banners of the slot should fit new size.
+	slot := entities.Slot{InnerID: slotID, Width: width, Height: height}
+	for _, banner := range banners {
+		if !slot.Fits(banner.Creative) {
+			err := entities.ErrCreativeNotFit(slotID, banner.InnerID, pageURL, banner.Width, banner.Height)
+			return errors.Wrapf(err, ErrUpdateSlot, slotID, pageURL)
+		}
+	}
+	if err := r.slotRepo.UpdateSlot(ctx, pageURL, slotID, slotDescription, viewableTry, width, height); err != nil {
+		return errors.Wrapf(err, ErrUpdateSlot, slotID, pageURL)
+	}
+	// slot keys and tries are used by algorithm.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrUpdateSlot, slotID, pageURL)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) UpdateBanner(ctx context.Context, pageURL string, slotID, bannerID uint, bannerDescription string, creative entities.Creative) error {
+	banner, err := r.bannerRepo.GetBanner(ctx, pageURL, slotID, bannerID)
+	if err != nil {
+		return errors.Wrapf(err, ErrUpdateBanner, bannerID, pageURL, slotID)
+	}
+	// the banner is shared by slots it is attached to, so the creative should fit all of them.
+	trees, err := r.treeRepo.GetPageTrees(ctx, "")
+	if err != nil {
+		return errors.Wrapf(err, ErrUpdateBanner, bannerID, pageURL, slotID)
+	}
+	for _, tree := range trees {
+		for _, slot := range tree.Slots {
+			for _, b := range slot.Banners {
+				if b.InnerID == banner.InnerID && b.Description == banner.Description && !slot.Fits(creative) {
+					err := entities.ErrCreativeNotFit(slot.InnerID, bannerID, tree.URL, creative.Width, creative.Height)
+					return errors.Wrapf(err, ErrUpdateBanner, bannerID, pageURL, slotID)
+				}
+			}
+		}
+	}
+	if err := r.bannerRepo.UpdateBanner(ctx, pageURL, slotID, bannerID, bannerDescription, creative); err != nil {
+		return errors.Wrapf(err, ErrUpdateBanner, bannerID, pageURL, slotID)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) AddBannerToSlot(ctx context.Context, pageURL string, slotID uint, bannerID uint, bannerDescription string, creative entities.Creative) error {
+	slot, err := r.slotRepo.GetSlot(ctx, pageURL, slotID)
+	if err != nil {
+		return errors.Wrapf(err, ErrAddBanner, bannerID, bannerDescription, pageURL, slotID)
+	}
+	if !slot.Fits(creative) {
+		err := entities.ErrCreativeNotFit(slotID, bannerID, pageURL, creative.Width, creative.Height)
+		return errors.Wrapf(err, ErrAddBanner, bannerID, bannerDescription, pageURL, slotID)
+	}
+	if err := r.bannerRepo.AddBannerToSlot(ctx, pageURL, slotID, bannerID, bannerDescription, creative); err != nil {
 		return errors.Wrapf(err, ErrAddBanner, bannerID, bannerDescription, pageURL, slotID)
 	}
 	return nil
 }
 
-func (r *RotatorInteractor) DeleteBannerFromSlot(pageURL string, slotID, bannerID uint) error {
-	if err := r.bannerRepo.DeleteBannerFromSlot(pageURL, slotID, bannerID); err != nil {
+func (r *RotatorInteractor) DeleteBannerFromSlot(ctx context.Context, pageURL string, slotID, bannerID uint) error {
+	if err := r.bannerRepo.DeleteBannerFromSlot(ctx, pageURL, slotID, bannerID); err != nil {
 		return errors.Wrapf(err, ErrDeleteBanner, bannerID, pageURL, slotID)
 	}
 	return nil
 }
 
-func (r *RotatorInteractor) DeleteAllBannersFormSlot(pageURL string, slotID uint) error {
-	if err := r.bannerRepo.DeleteAllBannersFormSlot(pageURL, slotID); err != nil {
+func (r *RotatorInteractor) DeleteAllBannersFormSlot(ctx context.Context, pageURL string, slotID uint) error {
+	if err := r.bannerRepo.DeleteAllBannersFormSlot(ctx, pageURL, slotID); err != nil {
 		return errors.Wrapf(err, ErrDeleteBanners, pageURL, slotID)
 	}
 	return nil
 }
 
-func (r *RotatorInteractor) ClickByBanner(pageURL string, slotID, bannerID, userAge uint, userSex string) error {
-	groupDescription := r.userGroups.findGroup(userAge, userSex)
-	err := r.nextBannerAlgo.UpdateReward(pageURL, slotID, bannerID, groupDescription)
+func (r *RotatorInteractor) AddCatalogBanner(ctx context.Context, bannerID uint, bannerDescription string, creative entities.Creative) error {
+	if err := r.catalogRepo.AddCatalogBanner(ctx, bannerID, bannerDescription, creative); err != nil {
+		return errors.Wrapf(err, ErrAddCatalogBanner, bannerID)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) GetCatalogBanners(ctx context.Context) (banners []entities.Banner, err error) {
+	if banners, err = r.catalogRepo.GetCatalogBanners(ctx); err != nil {
+		return nil, errors.Wrap(err, ErrGetCatalogBanners)
+	}
+	return banners, nil
+}
+
+func (r *RotatorInteractor) DeleteCatalogBanner(ctx context.Context, bannerID uint) error {
+	if err := r.catalogRepo.DeleteCatalogBanner(ctx, bannerID); err != nil {
+		return errors.Wrapf(err, ErrRemoveFromCatalog, bannerID)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) AttachBanner(ctx context.Context, pageURL string, slotID, bannerID uint) error {
+	slot, err := r.slotRepo.GetSlot(ctx, pageURL, slotID)
+	if err != nil {
+		return errors.Wrapf(err, ErrAttachBanner, bannerID, pageURL, slotID)
+	}
+	banners, err := r.catalogRepo.GetCatalogBanners(ctx)
+	if err != nil {
+		return errors.Wrapf(err, ErrAttachBanner, bannerID, pageURL, slotID)
+	}
+	for _, banner := range banners {
+		if banner.InnerID == bannerID && !slot.Fits(banner.Creative) {
+			err := entities.ErrCreativeNotFit(slotID, bannerID, pageURL, banner.Width, banner.Height)
+			return errors.Wrapf(err, ErrAttachBanner, bannerID, pageURL, slotID)
+		}
+	}
+	if err := r.catalogRepo.AttachBanner(ctx, pageURL, slotID, bannerID); err != nil {
+		return errors.Wrapf(err, ErrAttachBanner, bannerID, pageURL, slotID)
+	}
+	// add banner arm to algorithm.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrAttachBanner, bannerID, pageURL, slotID)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) DetachBanner(ctx context.Context, pageURL string, slotID, bannerID uint) error {
+	if err := r.bannerRepo.DeleteBannerFromSlot(ctx, pageURL, slotID, bannerID); err != nil {
+		return errors.Wrapf(err, ErrDetachBanner, bannerID, pageURL, slotID)
+	}
+	// remove banner arm from algorithm.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrDetachBanner, bannerID, pageURL, slotID)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) GetBannerStat(ctx context.Context, bannerID uint) (GroupStats, error) {
+	actions, err := r.catalogRepo.GetBannerActions(ctx, bannerID)
+	if err != nil {
+		return nil, errors.Wrapf(err, ErrGetBannerStat, bannerID)
+	}
+	return actions, nil
+}
+
+func (r *RotatorInteractor) ClickByBanner(ctx context.Context, pageURL string, slotID, bannerID, userAge uint, userSex string, attributes map[string]string) error {
+	groupDescription := r.userGroups.findGroup(userAge, userSex, attributes)
+	err := r.nextBannerAlgo.UpdateReward(ctx, pageURL, slotID, bannerID, groupDescription)
 	if e, ok := err.(*AlgoError); e != nil && ok && e.Temporary() {
 		//update schema
-		if err := r.Init(); err != nil {
+		if err := r.Init(ctx); err != nil {
 			return errors.Wrapf(err, ErrClickOnBanner, bannerID, pageURL, slotID)
 		}
-		if err := r.nextBannerAlgo.UpdateReward(pageURL, slotID, bannerID, groupDescription); err != nil {
+		err := r.nextBannerAlgo.UpdateReward(ctx, pageURL, slotID, bannerID, groupDescription)
+		if err := notFoundAfterInit(err, entities.ErrBannerNotFound(slotID, bannerID, pageURL)); err != nil {
 			return errors.Wrapf(err, ErrClickOnBanner, bannerID, pageURL, slotID)
 		}
 	}
 	e := entities.Event{
-		EventType: "click",
-		DT:        time.Now(),
-		PageURL:   pageURL,
-		SlotID:    slotID,
-		BannerID:  bannerID,
-		UserAge:   userAge,
-		UserSex:   userSex,
+		EventType:        "click",
+		DT:               time.Now(),
+		PageURL:          pageURL,
+		SlotID:           slotID,
+		BannerID:         bannerID,
+		UserAge:          userAge,
+		UserSex:          userSex,
+		GroupDescription: groupDescription,
 	}
-	go func() {
-		if err := r.eventQueue.Push(e); err != nil {
-			r.logger.Log(context.TODO(), errors.Wrapf(err, ErrClickOnBanner, bannerID, pageURL, slotID))
+	r.statsBus.Publish(e)
+	go func(ctx context.Context) {
+		if err := r.eventQueue.Push(ctx, e); err != nil {
+			r.logger.Log(ctx, errors.Wrapf(err, ErrClickOnBanner, bannerID, pageURL, slotID))
 		}
-	}()
+	}(detach(ctx))
 	return nil
 }
 
-func (r *RotatorInteractor) GetNextBanner(pageURL string, slotID, userAge uint, userSex string) (bannerID uint, err error) {
-	groupDescription := r.userGroups.findGroup(userAge, userSex)
-	bannerID, err = r.nextBannerAlgo.GetNext(pageURL, slotID, groupDescription)
+func (r *RotatorInteractor) GetNextBanner(ctx context.Context, pageURL string, slotID, userAge uint, userSex string, attributes map[string]string, pageViewID string) (bannerID uint, err error) {
+	groupDescription := r.userGroups.findGroup(userAge, userSex, attributes)
+	var served []uint
+	var view *pageView
+	if pageViewID != "" {
+		view = r.pageViews.lock(pageViewID)
+		defer view.Unlock()
+		served = view.served(slotID)
+	}
+	filter := func() ArmFilter {
+		return combineFilters(
+			r.targetings.filter(groupDescription, userAge, userSex),
+			r.tags.filter(served),
+			r.tags.allowed(r.pageSettings.get(pageURL)),
+		)
+	}
+	bannerID, err = r.nextBannerAlgo.GetNext(ctx, pageURL, slotID, groupDescription, filter())
 	if e, ok := err.(*AlgoError); e != nil && ok && e.Temporary() {
 		//update schema
-		if err := r.Init(); err != nil {
+		if err := r.Init(ctx); err != nil {
 			return 0, errors.Wrapf(err, ErrGetNextBanner, pageURL, slotID)
 		}
-		bannerID, err = r.nextBannerAlgo.GetNext(pageURL, slotID, groupDescription)
-		if err != nil {
+		bannerID, err = r.nextBannerAlgo.GetNext(ctx, pageURL, slotID, groupDescription, filter())
+		err = notFoundAfterInit(err, entities.ErrSlotNotFound(slotID, pageURL))
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, ErrGetNextBanner, pageURL, slotID)
+	}
+	if view != nil {
+		view.add(slotID, bannerID)
+	}
+	// slots with viewable tries are updated by ViewBanner.
+	if !r.viewableSlots.isViewable(pageURL, slotID) {
+		if err := r.updateTry(ctx, pageURL, slotID, bannerID, groupDescription); err != nil {
 			return 0, errors.Wrapf(err, ErrGetNextBanner, pageURL, slotID)
 		}
 	}
-	err = r.nextBannerAlgo.UpdateTry(pageURL, slotID, bannerID, groupDescription)
+	e := entities.Event{
+		EventType:        "show",
+		DT:               time.Now(),
+		PageURL:          pageURL,
+		SlotID:           slotID,
+		BannerID:         bannerID,
+		UserAge:          userAge,
+		UserSex:          userSex,
+		GroupDescription: groupDescription,
+	}
+	r.statsBus.Publish(e)
+	go func(ctx context.Context) {
+		if err := r.eventQueue.Push(ctx, e); err != nil {
+			r.logger.Log(ctx, errors.Wrapf(err, ErrGetNextBanner, pageURL, slotID))
+		}
+	}(detach(ctx))
+	return bannerID, nil
+}
+
+func (r *RotatorInteractor) ViewBanner(ctx context.Context, pageURL string, slotID, bannerID, userAge uint, userSex string, attributes map[string]string) error {
+	groupDescription := r.userGroups.findGroup(userAge, userSex, attributes)
+	if r.viewableSlots.isViewable(pageURL, slotID) {
+		if err := r.updateTry(ctx, pageURL, slotID, bannerID, groupDescription); err != nil {
+			return errors.Wrapf(err, ErrViewBanner, bannerID, pageURL, slotID)
+		}
+	}
+	e := entities.Event{
+		EventType:        "view",
+		DT:               time.Now(),
+		PageURL:          pageURL,
+		SlotID:           slotID,
+		BannerID:         bannerID,
+		UserAge:          userAge,
+		UserSex:          userSex,
+		GroupDescription: groupDescription,
+	}
+	r.statsBus.Publish(e)
+	go func(ctx context.Context) {
+		if err := r.eventQueue.Push(ctx, e); err != nil {
+			r.logger.Log(ctx, errors.Wrapf(err, ErrViewBanner, bannerID, pageURL, slotID))
+		}
+	}(detach(ctx))
+	return nil
+}
+
+func (r *RotatorInteractor) updateTry(ctx context.Context, pageURL string, slotID, bannerID uint, groupDescription string) error {
+	err := r.nextBannerAlgo.UpdateTry(ctx, pageURL, slotID, bannerID, groupDescription)
 	if e, ok := err.(*AlgoError); e != nil && ok && e.Temporary() {
 		//update schema
-		if err := r.Init(); err != nil {
-			return 0, errors.Wrapf(err, ErrGetNextBanner, pageURL, slotID)
+		if err := r.Init(ctx); err != nil {
+			return err
 		}
-		err := r.nextBannerAlgo.UpdateTry(pageURL, slotID, bannerID, groupDescription)
-		if err != nil {
-			return 0, errors.Wrapf(err, ErrGetNextBanner, pageURL, slotID)
+		err := r.nextBannerAlgo.UpdateTry(ctx, pageURL, slotID, bannerID, groupDescription)
+		return notFoundAfterInit(err, entities.ErrBannerNotFound(slotID, bannerID, pageURL))
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) AddAdvertiser(ctx context.Context, advertiserName string) error {
+	if err := r.advertiserRepo.AddAdvertiser(ctx, advertiserName); err != nil {
+		return errors.Wrapf(err, ErrAddAdvertiser, advertiserName)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) AddCampaign(ctx context.Context, advertiserName, campaignName string) error {
+	if err := r.campaignRepo.AddCampaign(ctx, advertiserName, campaignName); err != nil {
+		return errors.Wrapf(err, ErrAddCampaign, campaignName, advertiserName)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) AddBannerToCampaign(ctx context.Context, campaignName string, bannerID uint) error {
+	if err := r.campaignRepo.AddBannerToCampaign(ctx, campaignName, bannerID); err != nil {
+		return errors.Wrapf(err, ErrAddCampaignBanner, bannerID, campaignName)
+	}
+	// campaign can be paused.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrAddCampaignBanner, bannerID, campaignName)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) PauseCampaign(ctx context.Context, campaignName string) error {
+	return r.setCampaignPaused(ctx, campaignName, true)
+}
+
+func (r *RotatorInteractor) ResumeCampaign(ctx context.Context, campaignName string) error {
+	return r.setCampaignPaused(ctx, campaignName, false)
+}
+
+func (r *RotatorInteractor) setCampaignPaused(ctx context.Context, campaignName string, paused bool) error {
+	if err := r.campaignRepo.SetCampaignPaused(ctx, campaignName, paused); err != nil {
+		return errors.Wrapf(err, ErrPauseCampaign, campaignName)
+	}
+	// update rotated banners.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrPauseCampaign, campaignName)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) PauseAdvertiser(ctx context.Context, advertiserName string) error {
+	return r.setAdvertiserPaused(ctx, advertiserName, true)
+}
+
+func (r *RotatorInteractor) ResumeAdvertiser(ctx context.Context, advertiserName string) error {
+	return r.setAdvertiserPaused(ctx, advertiserName, false)
+}
+
+func (r *RotatorInteractor) setAdvertiserPaused(ctx context.Context, advertiserName string, paused bool) error {
+	if err := r.advertiserRepo.SetAdvertiserPaused(ctx, advertiserName, paused); err != nil {
+		return errors.Wrapf(err, ErrPauseAdvertiser, advertiserName)
+	}
+	// update rotated banners.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrPauseAdvertiser, advertiserName)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) GetCampaignStat(ctx context.Context, campaignName string) (GroupStats, error) {
+	actions, err := r.campaignRepo.GetCampaignActions(ctx, campaignName)
+	if err != nil {
+		return nil, errors.Wrapf(err, ErrGetCampaignStat, campaignName)
+	}
+	return actions, nil
+}
+
+func (r *RotatorInteractor) GetAdvertiserStat(ctx context.Context, advertiserName string) (GroupStats, error) {
+	actions, err := r.advertiserRepo.GetAdvertiserActions(ctx, advertiserName)
+	if err != nil {
+		return nil, errors.Wrapf(err, ErrGetAdvertiserStat, advertiserName)
+	}
+	return actions, nil
+}
+
+func (r *RotatorInteractor) GetGroups(ctx context.Context) (groups []entities.Group, defaultGroupDescription string, err error) {
+	if groups, defaultGroupDescription, err = r.groupRepo.GetGroups(ctx); err != nil {
+		return nil, "", errors.Wrap(err, ErrGetGroups)
+	}
+	return groups, defaultGroupDescription, nil
+}
+
+func (r *RotatorInteractor) GetGroupRules(ctx context.Context) (rules map[string][]entities.Rule, err error) {
+	if rules, err = r.groupRepo.GetGroupRules(ctx); err != nil {
+		return nil, errors.Wrap(err, ErrGetGroups)
+	}
+	return rules, nil
+}
+
+func (r *RotatorInteractor) AddGroup(ctx context.Context, group entities.Group, rules []entities.Rule) error {
+	if err := r.validateGroup(ctx, "", group, rules); err != nil {
+		return errors.Wrapf(err, ErrAddGroup, group.Description)
+	}
+	if err := r.groupRepo.AddGroup(ctx, group, rules); err != nil {
+		return errors.Wrapf(err, ErrAddGroup, group.Description)
+	}
+	// refresh user groups and algorithm state.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrAddGroup, group.Description)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) UpdateGroup(ctx context.Context, groupDescription string, group entities.Group, rules []entities.Rule) error {
+	if err := r.validateGroup(ctx, groupDescription, group, rules); err != nil {
+		return errors.Wrapf(err, ErrUpdateGroup, groupDescription)
+	}
+	if err := r.groupRepo.UpdateGroup(ctx, groupDescription, group, rules); err != nil {
+		return errors.Wrapf(err, ErrUpdateGroup, groupDescription)
+	}
+	// refresh user groups and algorithm state.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrUpdateGroup, groupDescription)
+	}
+	return nil
+}
+
+// validateGroup validates the group against all groups except the updated one.
+func (r *RotatorInteractor) validateGroup(ctx context.Context, groupDescription string, group entities.Group, rules []entities.Rule) error {
+	groups, _, err := r.groupRepo.GetGroups(ctx)
+	if err != nil {
+		return err
+	}
+	groupRules, err := r.groupRepo.GetGroupRules(ctx)
+	if err != nil {
+		return err
+	}
+	others := make([]entities.Group, 0, len(groups))
+	for _, g := range groups {
+		if g.Description != groupDescription {
+			others = append(others, g)
 		}
 	}
-	e := entities.Event{
-		EventType: "show",
-		DT:        time.Now(),
-		PageURL:   pageURL,
-		SlotID:    slotID,
-		BannerID:  bannerID,
-		UserAge:   userAge,
-		UserSex:   userSex,
-	}
-	go func() {
-		if err := r.eventQueue.Push(e); err != nil {
-			r.logger.Log(context.TODO(), errors.Wrapf(err, ErrGetNextBanner, pageURL, slotID))
-		}
-	}()
-	return bannerID, nil
+	return entities.ValidateGroup(group, rules, others, groupRules)
+}
+
+func (r *RotatorInteractor) DeleteGroup(ctx context.Context, groupDescription string) error {
+	if err := r.groupRepo.DeleteGroup(ctx, groupDescription); err != nil {
+		return errors.Wrapf(err, ErrDeleteGroup, groupDescription)
+	}
+	// refresh user groups and algorithm state.
+	if err := r.Init(ctx); err != nil {
+		return errors.Wrapf(err, ErrDeleteGroup, groupDescription)
+	}
+	return nil
 }
 
-func (r *RotatorInteractor) initUserGroups() (err error) {
-	groups, defaultGroupDescription, err := r.groupRepo.GetGroups()
+func (r *RotatorInteractor) initUserGroups(ctx context.Context) (err error) {
+	groups, defaultGroupDescription, err := r.groupRepo.GetGroups(ctx)
 	if err != nil {
 		return err
 	}
-	r.userGroups = userGroups{
-		groups:             groups,
-		defaultDescription: defaultGroupDescription,
+	rules, err := r.groupRepo.GetGroupRules(ctx)
+	if err != nil {
+		return err
 	}
+	r.userGroups.set(groups, rules, defaultGroupDescription)
 	return
 }
 
 type userGroups struct {
+	sync.RWMutex
 	groups             []entities.Group
+	rules              map[string][]entities.Rule
 	defaultDescription string
 }
 
-func (ug *userGroups) findGroup(userAge uint, userSex string) (groupDescription string) {
+func (ug *userGroups) set(groups []entities.Group, rules map[string][]entities.Rule, defaultDescription string) {
+	ug.Lock()
+	defer ug.Unlock()
+	// groups with greater priority are matched first.
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Priority > groups[j].Priority
+	})
+	ug.groups = groups
+	ug.rules = rules
+	ug.defaultDescription = defaultDescription
+}
+
+func (ug *userGroups) findGroup(userAge uint, userSex string, attributes map[string]string) (groupDescription string) {
+	ug.RLock()
+	defer ug.RUnlock()
 	groupDescription = ug.defaultDescription
 	for _, group := range ug.groups {
-		if group.Sex == userSex && group.MinAge <= userAge && group.MaxAge >= userAge {
+		if group.IsDefault {
+			continue
+		}
+		if group.Match(userAge, userSex) && entities.MatchRules(ug.rules[group.Description], attributes) {
 			return group.Description
 		}
 	}
 	return
 }
+
+func (r *RotatorInteractor) SetBannerTargeting(ctx context.Context, bannerID uint, targeting entities.Targeting) error {
+	groups, _, err := r.groupRepo.GetGroups(ctx)
+	if err != nil {
+		return errors.Wrapf(err, ErrSetTargeting, bannerID)
+	}
+	if err := entities.ValidateTargeting(targeting, groups); err != nil {
+		return errors.Wrapf(err, ErrSetTargeting, bannerID)
+	}
+	if err := r.targetingRepo.SetBannerTargeting(ctx, bannerID, targeting); err != nil {
+		return errors.Wrapf(err, ErrSetTargeting, bannerID)
+	}
+	if err := r.initTargetings(ctx); err != nil {
+		return errors.Wrapf(err, ErrSetTargeting, bannerID)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) DeleteBannerTargeting(ctx context.Context, bannerID uint) error {
+	if err := r.targetingRepo.DeleteBannerTargeting(ctx, bannerID); err != nil {
+		return errors.Wrapf(err, ErrDeleteTargeting, bannerID)
+	}
+	if err := r.initTargetings(ctx); err != nil {
+		return errors.Wrapf(err, ErrDeleteTargeting, bannerID)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) GetBannerTargeting(ctx context.Context, bannerID uint) (targeting entities.Targeting, err error) {
+	targetings, err := r.targetingRepo.GetTargetings(ctx)
+	if err != nil {
+		return entities.Targeting{}, errors.Wrapf(err, ErrGetTargeting, bannerID)
+	}
+	return targetings[bannerID], nil
+}
+
+func (r *RotatorInteractor) initTargetings(ctx context.Context) error {
+	targetings, err := r.targetingRepo.GetTargetings(ctx)
+	if err != nil {
+		return errors.Wrap(err, ErrInitTargetings)
+	}
+	r.targetings.set(targetings)
+	return nil
+}
+
+func (r *RotatorInteractor) SetBannerTags(ctx context.Context, bannerID uint, tags entities.Tags) error {
+	if err := r.tagsRepo.SetBannerTags(ctx, bannerID, tags); err != nil {
+		return errors.Wrapf(err, ErrSetTags, bannerID)
+	}
+	if err := r.initTags(ctx); err != nil {
+		return errors.Wrapf(err, ErrSetTags, bannerID)
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) GetBannerTags(ctx context.Context, bannerID uint) (tags entities.Tags, err error) {
+	ts, err := r.tagsRepo.GetTags(ctx)
+	if err != nil {
+		return entities.Tags{}, errors.Wrapf(err, ErrGetTags, bannerID)
+	}
+	return ts[bannerID], nil
+}
+
+func (r *RotatorInteractor) initTags(ctx context.Context) error {
+	tags, err := r.tagsRepo.GetTags(ctx)
+	if err != nil {
+		return errors.Wrap(err, ErrInitTags)
+	}
+	r.tags.set(tags)
+	return nil
+}
+
+type bannerTargetings struct {
+	sync.RWMutex
+	targetings map[uint]entities.Targeting
+}
+
+func (bt *bannerTargetings) set(targetings map[uint]entities.Targeting) {
+	bt.Lock()
+	defer bt.Unlock()
+	bt.targetings = targetings
+}
+
+// filter returns arm filter which excludes banners targeted to other users.
+func (bt *bannerTargetings) filter(groupDescription string, userAge uint, userSex string) ArmFilter {
+	bt.RLock()
+	defer bt.RUnlock()
+	if len(bt.targetings) == 0 {
+		return nil
+	}
+	targetings := bt.targetings
+	return func(bannerID uint) bool {
+		targeting, ok := targetings[bannerID]
+		return !ok || targeting.Allow(groupDescription, userAge, userSex)
+	}
+}
+
+type pageSettings struct {
+	sync.RWMutex
+	settings map[string]entities.PageSettings
+}
+
+func (ps *pageSettings) set(settings map[string]entities.PageSettings) {
+	ps.Lock()
+	defer ps.Unlock()
+	ps.settings = settings
+}
+
+func (ps *pageSettings) get(pageURL string) entities.PageSettings {
+	ps.RLock()
+	defer ps.RUnlock()
+	return ps.settings[pageURL]
+}
+
+type viewableSlots struct {
+	sync.RWMutex
+	slots map[string]map[uint]bool
+}
+
+func (vs *viewableSlots) init(pages Pages) {
+	vs.Lock()
+	defer vs.Unlock()
+	vs.slots = make(map[string]map[uint]bool)
+	for page, slots := range pages {
+		vs.slots[page.URL] = make(map[uint]bool)
+		for slot := range slots {
+			vs.slots[page.URL][slot.InnerID] = slot.ViewableTry
+		}
+	}
+}
+
+func (vs *viewableSlots) isViewable(pageURL string, slotID uint) bool {
+	vs.RLock()
+	defer vs.RUnlock()
+	return vs.slots[pageURL][slotID]
+}
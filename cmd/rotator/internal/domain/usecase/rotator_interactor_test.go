@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
+	"github.com/shipa988/banner_rotator/internal/data/repository"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+// emptyAlgo has no state for any slot like an algorithm initialized before the slot was added.
+type emptyAlgo struct {
+	nopAlgo
+}
+
+func (emptyAlgo) GetNext(context.Context, string, uint, string, ArmFilter) (uint, error) {
+	return 0, &AlgoError{Mess: "no state", IsOldSchema: true}
+}
+
+func (emptyAlgo) UpdateReward(context.Context, string, uint, uint, string) error {
+	return &AlgoError{Mess: "no state", IsOldSchema: true}
+}
+
+func TestRotatorInteractor_NotFoundAfterInit(t *testing.T) {
+	ctx := context.Background()
+	logger := zaplogger.NewLogger(ioutil.Discard, false)
+	rotator, err := NewRotatorInteractor(repository.NewMemRepo(logger), nopQueue{}, emptyAlgo{}, NewStatsBus(time.Second), logger)
+	require.NoError(t, err)
+
+	_, err = rotator.GetNextBanner(ctx, "site.com", 1, 30, "man", nil, "")
+	require.ErrorIs(t, err, entities.ErrNotFound)
+	require.NotErrorIs(t, err, entities.ErrSchemaOutOfDate)
+
+	err = rotator.ClickByBanner(ctx, "site.com", 1, 1, 30, "man", nil)
+	require.ErrorIs(t, err, entities.ErrNotFound)
+	require.NotErrorIs(t, err, entities.ErrSchemaOutOfDate)
+}
+
+// treeOnlyRepo fails queries of banner actions one by one.
+type treeOnlyRepo struct {
+	*repository.MemRepo
+}
+
+func (treeOnlyRepo) GetActions(context.Context, string, uint, uint) (map[entities.Group]entities.Action, error) {
+	return nil, errors.New("actions are queried by banner")
+}
+
+func TestRotatorInteractor_GetBannerInfos(t *testing.T) {
+	ctx := context.Background()
+	logger := zaplogger.NewLogger(ioutil.Discard, false)
+	repo := treeOnlyRepo{MemRepo: repository.NewMemRepo(logger)}
+	oldMan := entities.Group{Description: "old man", Sex: "man", MinAge: 61, MaxAge: 150}
+	require.NoError(t, repo.AddGroup(ctx, oldMan, nil))
+	require.NoError(t, repo.AddSlot(ctx, "site.com", 1, "top", false, 0, 0))
+	require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 1, 1, "sale", entities.Creative{}))
+	require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 1, 2, "promo", entities.Creative{}))
+	_, err := repo.AddActions(ctx, []entities.ActionIncrement{{PageURL: "site.com", SlotID: 1, BannerID: 2, GroupDescription: "old man", Hour: time.Now(), Action: entities.Action{Clicks: 1, Shows: 2}}})
+	require.NoError(t, err)
+	rotator, err := NewRotatorInteractor(repo, nopQueue{}, nopAlgo{}, NewStatsBus(time.Second), logger)
+	require.NoError(t, err)
+
+	banners, err := rotator.GetBannerInfos(ctx, "site.com", 1)
+	require.NoError(t, err)
+	require.Len(t, banners, 2)
+	require.Equal(t, entities.Banner{InnerID: 1, Description: "sale"}, banners[0].Banner)
+	require.Equal(t, GroupStats{oldMan: {}}, banners[0].Stats)
+	require.Equal(t, entities.Banner{InnerID: 2, Description: "promo"}, banners[1].Banner)
+	require.Equal(t, GroupStats{oldMan: {Clicks: 1, Shows: 2}}, banners[1].Stats)
+
+	_, err = rotator.GetBannerInfos(ctx, "site.com", 2)
+	require.ErrorIs(t, err, entities.ErrNotFound)
+	_, err = rotator.GetBannerInfos(ctx, "other.com", 1)
+	require.ErrorIs(t, err, entities.ErrNotFound)
+	_, err = rotator.GetBannerInfos(ctx, "", 1)
+	require.ErrorIs(t, err, entities.ErrInvalidArgument)
+}
+
+func TestUserGroups_FindGroup(t *testing.T) {
+	ug := &userGroups{}
+	groups := []entities.Group{
+		{Description: "young man", Sex: "man", MinAge: 1, MaxAge: 40},
+		{Description: "mobile", Priority: 1},
+		{Description: "unknown", Sex: "unknown", IsDefault: true},
+	}
+	rules := map[string][]entities.Rule{"mobile": {{Attribute: "device", Operator: entities.RuleEqual, Value: "mobile"}}}
+	ug.set(groups, rules, "unknown")
+
+	require.Equal(t, "mobile", ug.findGroup(30, "man", map[string]string{"device": "mobile"}))
+	require.Equal(t, "mobile", ug.findGroup(70, "women", map[string]string{"device": "mobile"}))
+	require.Equal(t, "young man", ug.findGroup(30, "man", nil))
+	require.Equal(t, "unknown", ug.findGroup(0, "unknown", nil))
+	require.Equal(t, "unknown", ug.findGroup(70, "women", nil))
+}
+
+// tryAlgo serves the first banner and counts tries by slot.
+type tryAlgo struct {
+	nopAlgo
+	mu    sync.Mutex
+	tries map[uint]int
+}
+
+func (a *tryAlgo) GetNext(context.Context, string, uint, string, ArmFilter) (uint, error) {
+	return 1, nil
+}
+
+func (a *tryAlgo) UpdateTry(ctx context.Context, pageURL string, slotID, bannerID uint, groupDescription string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.tries[slotID]++
+	return nil
+}
+
+func TestRotatorInteractor_ViewableTry(t *testing.T) {
+	ctx := context.Background()
+	logger := zaplogger.NewLogger(ioutil.Discard, false)
+	repo := repository.NewMemRepo(logger)
+	require.NoError(t, repo.AddSlot(ctx, "site.com", 1, "top", true, 0, 0))
+	require.NoError(t, repo.AddSlot(ctx, "site.com", 2, "bottom", false, 0, 0))
+	for slotID := uint(1); slotID <= 2; slotID++ {
+		require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", slotID, 1, "sale", entities.Creative{}))
+	}
+	algo := &tryAlgo{tries: make(map[uint]int)}
+	rotator, err := NewRotatorInteractor(repo, nopQueue{}, algo, NewStatsBus(time.Second), logger)
+	require.NoError(t, err)
+	require.NoError(t, rotator.Init(ctx))
+
+	for slotID := uint(1); slotID <= 2; slotID++ {
+		_, err := rotator.GetNextBanner(ctx, "site.com", slotID, 30, "man", nil, "")
+		require.NoError(t, err)
+	}
+	// the viewable slot counts the try on view only.
+	require.Equal(t, map[uint]int{2: 1}, algo.tries)
+
+	for slotID := uint(1); slotID <= 2; slotID++ {
+		require.NoError(t, rotator.ViewBanner(ctx, "site.com", slotID, 1, 30, "man", nil))
+	}
+	require.Equal(t, map[uint]int{1: 1, 2: 1}, algo.tries)
+}
+
+func TestRotatorInteractor_UpdateSharedBanner(t *testing.T) {
+	ctx := context.Background()
+	logger := zaplogger.NewLogger(ioutil.Discard, false)
+	repo := repository.NewMemRepo(logger)
+	require.NoError(t, repo.AddSlot(ctx, "site.com", 1, "top", false, 728, 250))
+	require.NoError(t, repo.AddSlot(ctx, "other.com", 2, "side", false, 300, 250))
+	small := entities.Creative{AssetURL: "sale.png", Width: 300, Height: 250}
+	require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 1, 1, "sale", small))
+	require.NoError(t, repo.AddBannerToSlot(ctx, "other.com", 2, 1, "sale", small))
+	rotator, err := NewRotatorInteractor(repo, nopQueue{}, nopAlgo{}, NewStatsBus(time.Second), logger)
+	require.NoError(t, err)
+
+	// the creative fits the slot of the request but not the other slot of the banner.
+	wide := entities.Creative{AssetURL: "sale.png", Width: 728, Height: 90}
+	err = rotator.UpdateBanner(ctx, "site.com", 1, 1, "sale", wide)
+	require.ErrorIs(t, err, entities.ErrInvalidArgument)
+	banner, err := repo.GetBanner(ctx, "other.com", 2, 1)
+	require.NoError(t, err)
+	require.Equal(t, small, banner.Creative)
+
+	require.NoError(t, rotator.UpdateBanner(ctx, "site.com", 1, 1, "sale", entities.Creative{AssetURL: "sale.png", Width: 250, Height: 250}))
+}
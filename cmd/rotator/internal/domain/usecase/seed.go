@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+const ErrSeed = "can't seed %v"
+
+// Fixture is the data which is seeded into the db, groups are seeded before pages.
+type Fixture struct {
+	Groups []FixtureGroup `yaml:"groups" json:"groups"`
+	Pages  []FixturePage  `yaml:"pages" json:"pages"`
+}
+
+type FixtureGroup struct {
+	Description string          `yaml:"description" json:"description"`
+	Sex         string          `yaml:"sex" json:"sex"`
+	MinAge      uint            `yaml:"minage" json:"minage"`
+	MaxAge      uint            `yaml:"maxage" json:"maxage"`
+	Priority    int             `yaml:"priority" json:"priority"`
+	Default     bool            `yaml:"default" json:"default"`
+	Rules       []entities.Rule `yaml:"rules" json:"rules"`
+}
+
+type FixturePage struct {
+	URL               string        `yaml:"url" json:"url"`
+	Algorithm         string        `yaml:"algorithm" json:"algorithm"`
+	AllowedCategories []string      `yaml:"allowedcategories" json:"allowedcategories"`
+	Slots             []FixtureSlot `yaml:"slots" json:"slots"`
+}
+
+type FixtureSlot struct {
+	ID          uint            `yaml:"id" json:"id"`
+	Description string          `yaml:"description" json:"description"`
+	ViewableTry bool            `yaml:"viewabletry" json:"viewabletry"`
+	Width       uint            `yaml:"width" json:"width"`
+	Height      uint            `yaml:"height" json:"height"`
+	Banners     []FixtureBanner `yaml:"banners" json:"banners"`
+}
+
+type FixtureBanner struct {
+	ID          uint              `yaml:"id" json:"id"`
+	Description string            `yaml:"description" json:"description"`
+	Creative    entities.Creative `yaml:"creative" json:"creative"`
+}
+
+func (g FixtureGroup) group() entities.Group {
+	return entities.Group{Description: g.Description, Sex: g.Sex, MinAge: g.MinAge, MaxAge: g.MaxAge, Priority: g.Priority, IsDefault: g.Default}
+}
+
+func (p FixturePage) settings() entities.PageSettings {
+	return entities.PageSettings{Algorithm: p.Algorithm, AllowedCategories: p.AllowedCategories}
+}
+
+func (s FixtureSlot) slot() entities.Slot {
+	return entities.Slot{InnerID: s.ID, Description: s.Description, ViewableTry: s.ViewableTry, Width: s.Width, Height: s.Height}
+}
+
+func (b FixtureBanner) banner() entities.Banner {
+	return entities.Banner{InnerID: b.ID, Description: b.Description, Creative: b.Creative}
+}
+
+// SeedReport has names of seeded entities, entities equal to the fixture are skipped.
+type SeedReport struct {
+	Created []string
+	Updated []string
+	Skipped []string
+}
+
+// Seed creates entities of the fixture and updates the existing ones which differ from it,
+// so seeding the same fixture again changes nothing.
+func (r *RotatorInteractor) Seed(ctx context.Context, fixture Fixture) (report SeedReport, err error) {
+	if err := r.seedGroups(ctx, fixture.Groups, &report); err != nil {
+		return report, err
+	}
+	for _, page := range fixture.Pages {
+		if err := r.seedPage(ctx, page, &report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func (r *RotatorInteractor) seedGroups(ctx context.Context, fixtureGroups []FixtureGroup, report *SeedReport) error {
+	groups, _, err := r.groupRepo.GetGroups(ctx)
+	if err != nil {
+		return errors.Wrapf(err, ErrSeed, "groups")
+	}
+	groupRules, err := r.groupRepo.GetGroupRules(ctx)
+	if err != nil {
+		return errors.Wrapf(err, ErrSeed, "groups")
+	}
+	existing := make(map[string]entities.Group, len(groups))
+	for _, group := range groups {
+		existing[group.Description] = group
+	}
+	for _, fixtureGroup := range fixtureGroups {
+		name := fmt.Sprintf("group %v", fixtureGroup.Description)
+		group, ok := existing[fixtureGroup.Description]
+		switch {
+		case !ok:
+			if err := r.AddGroup(ctx, fixtureGroup.group(), fixtureGroup.Rules); err != nil {
+				return errors.Wrapf(err, ErrSeed, name)
+			}
+			report.Created = append(report.Created, name)
+		case group != fixtureGroup.group() || !equalRules(groupRules[group.Description], fixtureGroup.Rules):
+			if err := r.UpdateGroup(ctx, group.Description, fixtureGroup.group(), fixtureGroup.Rules); err != nil {
+				return errors.Wrapf(err, ErrSeed, name)
+			}
+			report.Updated = append(report.Updated, name)
+		default:
+			report.Skipped = append(report.Skipped, name)
+		}
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) seedPage(ctx context.Context, fixturePage FixturePage, report *SeedReport) error {
+	name := fmt.Sprintf("page %v", fixturePage.URL)
+	pages, err := r.pageRepo.GetPageSettings(ctx)
+	if err != nil {
+		return errors.Wrapf(err, ErrSeed, name)
+	}
+	settings, ok := pages[fixturePage.URL]
+	switch {
+	case !ok:
+		if err := r.AddPage(ctx, fixturePage.URL, fixturePage.settings()); err != nil {
+			return errors.Wrapf(err, ErrSeed, name)
+		}
+		report.Created = append(report.Created, name)
+	case !equalSettings(settings, fixturePage.settings()):
+		if err := r.SetPageSettings(ctx, fixturePage.URL, fixturePage.settings()); err != nil {
+			return errors.Wrapf(err, ErrSeed, name)
+		}
+		report.Updated = append(report.Updated, name)
+	default:
+		report.Skipped = append(report.Skipped, name)
+	}
+
+	slots, err := r.slotRepo.GetSlotsByPageURL(ctx, fixturePage.URL)
+	if err != nil {
+		return errors.Wrapf(err, ErrSeed, name)
+	}
+	existing := make(map[uint]entities.Slot, len(slots))
+	for _, slot := range slots {
+		existing[slot.InnerID] = slot
+	}
+	for _, fixtureSlot := range fixturePage.Slots {
+		slot, ok := existing[fixtureSlot.ID]
+		if err := r.seedSlot(ctx, fixturePage.URL, fixtureSlot, slot, ok, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RotatorInteractor) seedSlot(ctx context.Context, pageURL string, fixtureSlot FixtureSlot, slot entities.Slot, exists bool, report *SeedReport) error {
+	name := fmt.Sprintf("slot %v/%v", pageURL, fixtureSlot.ID)
+	s := fixtureSlot.slot()
+	switch {
+	case !exists:
+		if err := r.AddSlot(ctx, pageURL, s.InnerID, s.Description, s.ViewableTry, s.Width, s.Height); err != nil {
+			return errors.Wrapf(err, ErrSeed, name)
+		}
+		report.Created = append(report.Created, name)
+	case slot != s:
+		if err := r.UpdateSlot(ctx, pageURL, s.InnerID, s.Description, s.ViewableTry, s.Width, s.Height); err != nil {
+			return errors.Wrapf(err, ErrSeed, name)
+		}
+		report.Updated = append(report.Updated, name)
+	default:
+		report.Skipped = append(report.Skipped, name)
+	}
+
+	banners, err := r.bannerRepo.GetBannersBySlotID(ctx, pageURL, fixtureSlot.ID)
+	if err != nil {
+		return errors.Wrapf(err, ErrSeed, name)
+	}
+	existing := make(map[uint]entities.Banner, len(banners))
+	for _, banner := range banners {
+		existing[banner.InnerID] = banner
+	}
+	for _, fixtureBanner := range fixtureSlot.Banners {
+		name := fmt.Sprintf("banner %v/%v/%v", pageURL, fixtureSlot.ID, fixtureBanner.ID)
+		b := fixtureBanner.banner()
+		banner, ok := existing[b.InnerID]
+		switch {
+		case !ok:
+			if err := r.AddBannerToSlot(ctx, pageURL, fixtureSlot.ID, b.InnerID, b.Description, b.Creative); err != nil {
+				return errors.Wrapf(err, ErrSeed, name)
+			}
+			report.Created = append(report.Created, name)
+		case banner != b:
+			if err := r.UpdateBanner(ctx, pageURL, fixtureSlot.ID, b.InnerID, b.Description, b.Creative); err != nil {
+				return errors.Wrapf(err, ErrSeed, name)
+			}
+			report.Updated = append(report.Updated, name)
+		default:
+			report.Skipped = append(report.Skipped, name)
+		}
+	}
+	return nil
+}
+
+func equalRules(a, b []entities.Rule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalSettings(a, b entities.PageSettings) bool {
+	if a.Algorithm != b.Algorithm || len(a.AllowedCategories) != len(b.AllowedCategories) {
+		return false
+	}
+	for i := range a.AllowedCategories {
+		if a.AllowedCategories[i] != b.AllowedCategories[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
+	"github.com/shipa988/banner_rotator/internal/data/repository"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+// nopAlgo keeps no state, seeding doesn't depend on the algorithm.
+type nopAlgo struct{}
+
+func (nopAlgo) GetNext(context.Context, string, uint, string, ArmFilter) (uint, error) { return 0, nil }
+func (nopAlgo) UpdateTry(context.Context, string, uint, uint, string) error            { return nil }
+func (nopAlgo) UpdateReward(context.Context, string, uint, uint, string) error         { return nil }
+func (nopAlgo) Init(context.Context, *Pages) error                                     { return nil }
+func (nopAlgo) RemovePage(context.Context, string) error                               { return nil }
+func (nopAlgo) Scores(context.Context, string, uint) (map[string]map[uint]float64, error) {
+	return nil, nil
+}
+
+func TestRotatorInteractor_Seed(t *testing.T) {
+	ctx := context.Background()
+	logger := zaplogger.NewLogger(ioutil.Discard, false)
+	rotator, err := NewRotatorInteractor(repository.NewMemRepo(logger), nil, nopAlgo{}, NewStatsBus(time.Second), logger)
+	require.NoError(t, err)
+	fixture := Fixture{
+		Groups: []FixtureGroup{
+			{Description: "young man", Sex: "man", MinAge: 0, MaxAge: 40},
+			{Description: "unknown age-sex group", Sex: "unknown", Default: true},
+		},
+		Pages: []FixturePage{
+			{URL: "mysite.com", Slots: []FixtureSlot{
+				{ID: 1, Description: "top", Banners: []FixtureBanner{{ID: 2, Description: "sale"}}},
+			}},
+		},
+	}
+
+	report, err := rotator.Seed(ctx, fixture)
+	require.NoError(t, err)
+	require.Equal(t, []string{"group young man", "group unknown age-sex group", "page mysite.com", "slot mysite.com/1", "banner mysite.com/1/2"}, report.Created)
+	require.Empty(t, report.Updated)
+
+	report, err = rotator.Seed(ctx, fixture)
+	require.NoError(t, err)
+	require.Empty(t, report.Created)
+	require.Empty(t, report.Updated)
+	require.Len(t, report.Skipped, 5)
+
+	fixture.Groups[0].MaxAge = 30
+	fixture.Pages[0].Slots[0].Banners[0].Creative = entities.Creative{AltText: "sale"}
+	report, err = rotator.Seed(ctx, fixture)
+	require.NoError(t, err)
+	require.Equal(t, []string{"group young man", "banner mysite.com/1/2"}, report.Updated)
+	_, err = rotator.groupRepo.GetGroup(ctx, 35, "man")
+	require.Error(t, err)
+	banner, err := rotator.GetBanner(ctx, "mysite.com", 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, "sale", banner.AltText)
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+const DefaultStatsInterval = time.Second * 5
+
+// StatKey identifies counters of the banner in the slot for the user group.
+type StatKey struct {
+	SlotID           uint
+	BannerID         uint
+	GroupDescription string
+}
+
+// StatDeltas are counter increments since the previous push.
+type StatDeltas map[StatKey]entities.Action
+
+func (d StatDeltas) merge(other StatDeltas) {
+	for key, action := range other {
+		sum := d[key]
+		sum.Clicks += action.Clicks
+		sum.Shows += action.Shows
+		sum.Views += action.Views
+		d[key] = sum
+	}
+}
+
+// StatsBus collects banner events in process and pushes changed counters of the page to its subscribers every interval.
+// Deltas are collected once per page and shared by all page subscribers.
+type StatsBus struct {
+	sync.Mutex
+	interval time.Duration
+	pages    map[string]*pageStats
+	stop     chan struct{}
+}
+
+type pageStats struct {
+	deltas      StatDeltas
+	subscribers map[*statsSubscriber]struct{}
+}
+
+type statsSubscriber struct {
+	ch chan StatDeltas
+	// pending are deltas not received by the slow subscriber yet.
+	pending StatDeltas
+}
+
+func NewStatsBus(interval time.Duration) *StatsBus {
+	if interval <= 0 {
+		interval = DefaultStatsInterval
+	}
+	return &StatsBus{
+		interval: interval,
+		pages:    make(map[string]*pageStats),
+	}
+}
+
+// Publish counts the event for subscribers of the event page.
+func (b *StatsBus) Publish(e entities.Event) {
+	b.Lock()
+	defer b.Unlock()
+	page, ok := b.pages[e.PageURL]
+	if !ok {
+		return
+	}
+	key := StatKey{SlotID: e.SlotID, BannerID: e.BannerID, GroupDescription: e.GroupDescription}
+	action := page.deltas[key]
+	switch e.EventType {
+	case "click":
+		action.Clicks++
+	case "show":
+		action.Shows++
+	case "view":
+		action.Views++
+	default:
+		return
+	}
+	page.deltas[key] = action
+}
+
+// Subscribe returns deltas of the page, the channel is closed when ctx is done.
+func (b *StatsBus) Subscribe(ctx context.Context, pageURL string) <-chan StatDeltas {
+	b.Lock()
+	defer b.Unlock()
+	page, ok := b.pages[pageURL]
+	if !ok {
+		page = &pageStats{
+			deltas:      StatDeltas{},
+			subscribers: make(map[*statsSubscriber]struct{}),
+		}
+		b.pages[pageURL] = page
+	}
+	sub := &statsSubscriber{ch: make(chan StatDeltas, 1)}
+	page.subscribers[sub] = struct{}{}
+	// push only while somebody listens.
+	if b.stop == nil {
+		b.stop = make(chan struct{})
+		go b.run(b.stop)
+	}
+	go func() {
+		<-ctx.Done()
+		b.unsubscribe(pageURL, sub)
+	}()
+	return sub.ch
+}
+
+func (b *StatsBus) unsubscribe(pageURL string, sub *statsSubscriber) {
+	b.Lock()
+	defer b.Unlock()
+	page := b.pages[pageURL]
+	delete(page.subscribers, sub)
+	close(sub.ch)
+	if len(page.subscribers) == 0 {
+		delete(b.pages, pageURL)
+	}
+	if len(b.pages) == 0 {
+		close(b.stop)
+		b.stop = nil
+	}
+}
+
+func (b *StatsBus) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.push()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (b *StatsBus) push() {
+	b.Lock()
+	defer b.Unlock()
+	for _, page := range b.pages {
+		deltas := page.deltas
+		page.deltas = StatDeltas{}
+		for sub := range page.subscribers {
+			if len(deltas) == 0 && sub.pending == nil {
+				continue
+			}
+			msg := deltas
+			if sub.pending != nil {
+				sub.pending.merge(deltas)
+				msg = sub.pending
+			}
+			select {
+			case sub.ch <- msg:
+				sub.pending = nil
+			default:
+				// subscriber is behind, keep own copy of deltas.
+				if sub.pending == nil {
+					sub.pending = StatDeltas{}
+					sub.pending.merge(deltas)
+				}
+			}
+		}
+	}
+}
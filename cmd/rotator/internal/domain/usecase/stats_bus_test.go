@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func TestStatsBus(t *testing.T) {
+	const pageURL = "mysite.com"
+	bus := NewStatsBus(time.Millisecond * 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	first := bus.Subscribe(ctx, pageURL)
+	second := bus.Subscribe(ctx, pageURL)
+
+	bus.Publish(entities.Event{EventType: "show", PageURL: pageURL, SlotID: 1, BannerID: 2, GroupDescription: "old man"})
+	bus.Publish(entities.Event{EventType: "show", PageURL: pageURL, SlotID: 1, BannerID: 2, GroupDescription: "old man"})
+	bus.Publish(entities.Event{EventType: "click", PageURL: pageURL, SlotID: 1, BannerID: 2, GroupDescription: "old man"})
+	bus.Publish(entities.Event{EventType: "show", PageURL: "other.com", SlotID: 1, BannerID: 2, GroupDescription: "old man"})
+
+	expected := StatDeltas{{SlotID: 1, BannerID: 2, GroupDescription: "old man"}: {Clicks: 1, Shows: 2}}
+	require.Equal(t, expected, <-first)
+	require.Equal(t, expected, <-second)
+
+	cancel()
+	for range first {
+	}
+	for range second {
+	}
+	bus.Lock()
+	defer bus.Unlock()
+	require.Empty(t, bus.pages)
+	require.Nil(t, bus.stop)
+}
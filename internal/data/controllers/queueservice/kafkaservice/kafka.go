@@ -16,6 +16,7 @@ const (
 	ErrNilReader = "reader to kafka is nil. Implement reader if you wanna to consume messages via InitReader() func"
 	ErrPush      = "could't push message"
 	ErrPull      = "could't pull message"
+	ErrCommit    = "could't commit messages"
 )
 
 var _ entities.EventQueue = (*KafkaManager)(nil)
@@ -54,35 +55,50 @@ func (k *KafkaManager) InitReader(consumerGroupID string, minBytesRead, maxBytes
 func (k *KafkaManager) CloseReader() {
 	k.reader.Close()
 }
-func (k *KafkaManager) Pull(context context.Context, events chan<- entities.Event) (err error) {
+
+// Pull fetches messages without committing them, the consumer commits them by Commit after they are stored.
+func (k *KafkaManager) Pull(ctx context.Context, events chan<- entities.QueuedEvent) error {
 	if k.reader == nil {
 		return fmt.Errorf(ErrNilReader)
 	}
 
-	e := entities.Event{}
-	m := kafka.Message{}
-	loop := true
-	for loop {
-		select {
-		case <-context.Done():
-			loop = false
-			break
-		default:
-			m, err = k.reader.ReadMessage(context)
-			if err != nil {
-				break
+	for {
+		m, err := k.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
 			}
-			if err = json.Unmarshal(m.Value, &e); err != nil {
-				break
-			}
-			events <- e
+			return errors.Wrap(err, ErrPull)
+		}
+		e := entities.QueuedEvent{Partition: m.Partition, Offset: m.Offset}
+		if err := json.Unmarshal(m.Value, &e.Event); err != nil {
+			// malformed messages are skipped, they are committed with the next events of the partition.
+			continue
 		}
+		select {
+		case events <- e:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (k *KafkaManager) Commit(ctx context.Context, events []entities.QueuedEvent) error {
+	if k.reader == nil {
+		return fmt.Errorf(ErrNilReader)
 	}
 
-	return errors.Wrap(err, ErrPull)
+	messages := make([]kafka.Message, 0, len(events))
+	for _, e := range events {
+		messages = append(messages, kafka.Message{Topic: k.topic, Partition: e.Partition, Offset: e.Offset})
+	}
+	if err := k.reader.CommitMessages(ctx, messages...); err != nil {
+		return errors.Wrap(err, ErrCommit)
+	}
+	return nil
 }
 
-func (k *KafkaManager) Push(event entities.Event) error {
+func (k *KafkaManager) Push(ctx context.Context, event entities.Event) error {
 	if k.writer == nil {
 		return fmt.Errorf(ErrNilWriter)
 	}
@@ -91,7 +107,7 @@ func (k *KafkaManager) Push(event entities.Event) error {
 	if err != nil {
 		return errors.Wrap(err, ErrPush)
 	}
-	if err := k.writer.WriteMessages(context.Background(),
+	if err := k.writer.WriteMessages(ctx,
 		kafka.Message{
 			Key:   []byte(event.EventType),
 			Value: mess,
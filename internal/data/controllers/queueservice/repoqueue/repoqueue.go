@@ -0,0 +1,58 @@
+package repoqueue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+const (
+	ErrPush         = "could't write event"
+	ErrPull         = "events are written on push, there is nothing to pull"
+	ErrSkippedEvent = `%v event of deleted banner id: "%v", page: "%v", slot id: "%v" or group: "%v" is skipped`
+)
+
+var _ entities.EventQueue = (*RepoQueue)(nil)
+
+// RepoQueue writes pushed events to the repository at once without an aggregator,
+// it is used by the rotator with the in-memory repository.
+type RepoQueue struct {
+	repo entities.ActionBatchRepository
+}
+
+func NewRepoQueue(repo entities.ActionBatchRepository) *RepoQueue {
+	return &RepoQueue{repo: repo}
+}
+
+func (q *RepoQueue) Push(ctx context.Context, event entities.Event) error {
+	inc := entities.ActionIncrement{
+		PageURL:          event.PageURL,
+		SlotID:           event.SlotID,
+		BannerID:         event.BannerID,
+		GroupDescription: event.GroupDescription,
+		Hour:             event.DT.UTC().Truncate(time.Hour),
+	}
+	if err := inc.Add(event.EventType); err != nil {
+		return errors.Wrap(err, ErrPush)
+	}
+	skipped, err := q.repo.AddActions(ctx, []entities.ActionIncrement{inc})
+	if err != nil {
+		return errors.Wrap(err, ErrPush)
+	}
+	if len(skipped) != 0 {
+		return errors.Errorf(ErrSkippedEvent, event.EventType, event.BannerID, event.PageURL, event.SlotID, event.GroupDescription)
+	}
+	return nil
+}
+
+func (q *RepoQueue) Pull(ctx context.Context, events chan<- entities.QueuedEvent) error {
+	return fmt.Errorf(ErrPull)
+}
+
+func (q *RepoQueue) Commit(ctx context.Context, events []entities.QueuedEvent) error {
+	return nil
+}
@@ -0,0 +1,44 @@
+package repoqueue
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
+	"github.com/shipa988/banner_rotator/internal/data/repository"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func TestRepoQueue_Push(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemRepo(zaplogger.NewLogger(ioutil.Discard, false))
+	repo.CreateDB()
+	oldMan := entities.Group{Description: "old man", Sex: "man", MinAge: 61, MaxAge: 150}
+	require.NoError(t, repo.AddGroup(ctx, oldMan, nil))
+	require.NoError(t, repo.AddSlot(ctx, "site.com", 1, "top", false, 0, 0))
+	require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 1, 1, "sale", entities.Creative{}))
+
+	queue := NewRepoQueue(repo)
+	event := entities.Event{DT: time.Now(), PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man"}
+	for _, eventType := range []string{"show", "show", "click"} {
+		event.EventType = eventType
+		require.NoError(t, queue.Push(ctx, event))
+	}
+	event.EventType = "hover"
+	require.Error(t, queue.Push(ctx, event))
+	event.EventType = "show"
+	event.BannerID = 2
+	require.Error(t, queue.Push(ctx, event))
+
+	actions, err := repo.GetActions(ctx, "site.com", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, entities.Action{Clicks: 1, Shows: 2}, actions[oldMan])
+	buckets, err := repo.GetBuckets(ctx, "site.com", 1, 1, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	require.Equal(t, entities.Action{Clicks: 1, Shows: 2}, buckets[0].Action)
+}
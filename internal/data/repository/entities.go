@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/jinzhu/gorm"
 
 	"github.com/shipa988/banner_rotator/internal/domain/entities"
@@ -21,20 +23,45 @@ type BannerSlot struct {
 
 type Banner struct {
 	gorm.Model
+	CampaignID uint
+	// Catalog banners are kept when detached from all slots.
+	Catalog bool `gorm:"NOT NULL; DEFAULT:false"`
 	entities.Banner
 	BannerSlots []*BannerSlot //`gorm:"many2many:banner_slots;"`
 }
 
+type Advertiser struct {
+	gorm.Model
+	entities.Advertiser
+	Campaigns []*Campaign
+}
+
+type Campaign struct {
+	gorm.Model
+	AdvertiserID uint `gorm:"NOT NULL"`
+	entities.Campaign
+	Banners []*Banner
+}
+
 type Page struct {
 	gorm.Model
 	entities.Page
-	Slots []*Slot
+	// comma separated categories.
+	AllowedCategories string
+	Slots             []*Slot
 }
 
 type Group struct {
 	gorm.Model
 	entities.Group
 	Events []*BannerEvent
+	Rules  []*GroupRule
+}
+
+type GroupRule struct {
+	gorm.Model
+	GroupID uint `gorm:"NOT NULL"`
+	entities.Rule
 }
 
 type BannerEvent struct {
@@ -43,3 +70,31 @@ type BannerEvent struct {
 	BannerSlotID uint `gorm:"UNIQUE_INDEX:BannerSlotID_GroupID; NOT NULL"`
 	GroupID      uint `gorm:"UNIQUE_INDEX:BannerSlotID_GroupID; NOT NULL"`
 }
+
+// BannerEventBucket keeps banner events of the user group during the hour.
+type BannerEventBucket struct {
+	gorm.Model
+	entities.Action
+	BannerSlotID uint      `gorm:"UNIQUE_INDEX:BannerSlotID_GroupID_Hour; NOT NULL"`
+	GroupID      uint      `gorm:"UNIQUE_INDEX:BannerSlotID_GroupID_Hour; NOT NULL"`
+	Hour         time.Time `gorm:"UNIQUE_INDEX:BannerSlotID_GroupID_Hour; NOT NULL"`
+}
+
+type BannerTargeting struct {
+	gorm.Model
+	BannerInnerID uint `gorm:"UNIQUE; NOT NULL"`
+	MinAge        uint
+	MaxAge        uint
+	Sex           string
+	// comma separated group descriptions.
+	IncludeGroups string
+	ExcludeGroups string
+}
+
+type BannerTags struct {
+	gorm.Model
+	BannerInnerID uint `gorm:"UNIQUE; NOT NULL"`
+	// comma separated tags.
+	Categories  string
+	Competitors string
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.ActionBatchRepository = (*MemRepo)(nil)
+
+func (r *MemRepo) AddActions(ctx context.Context, increments []entities.ActionIncrement) (skipped []entities.ActionIncrement, err error) {
+	for _, inc := range increments {
+		if err := validateZeroParam(inc.PageURL, inc.SlotID, inc.BannerID, inc.GroupDescription); err != nil {
+			return nil, err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, inc := range increments {
+		bannerSlot, err := r.getRepoBannerSlot(inc.PageURL, inc.SlotID, inc.BannerID)
+		if err != nil {
+			skipped = append(skipped, inc)
+			continue
+		}
+		group, err := r.getRepoGroupByDescription(inc.GroupDescription)
+		if err != nil {
+			skipped = append(skipped, inc)
+			continue
+		}
+		key := eventKey{bannerSlotID: bannerSlot.ID, groupID: group.ID}
+		event, ok := r.events[key]
+		if !ok {
+			event = &BannerEvent{Model: r.newModel(), BannerSlotID: bannerSlot.ID, GroupID: group.ID}
+			r.events[key] = event
+		}
+		addAction(&event.Action, inc.Action)
+		hour := inc.Hour.UTC().Truncate(time.Hour)
+		bucketKey := bucketKey{bannerSlotID: bannerSlot.ID, groupID: group.ID, hour: hour.Unix()}
+		bucket, ok := r.buckets[bucketKey]
+		if !ok {
+			bucket = &BannerEventBucket{Model: r.newModel(), BannerSlotID: bannerSlot.ID, GroupID: group.ID, Hour: hour}
+			r.buckets[bucketKey] = bucket
+		}
+		addAction(&bucket.Action, inc.Action)
+	}
+	return skipped, nil
+}
+
+func addAction(action *entities.Action, inc entities.Action) {
+	action.Clicks += inc.Clicks
+	action.Shows += inc.Shows
+	action.Views += inc.Views
+}
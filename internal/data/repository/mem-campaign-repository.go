@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.AdvertiserRepository = (*MemRepo)(nil)
+var _ entities.CampaignRepository = (*MemRepo)(nil)
+
+func (r *MemRepo) AddAdvertiser(ctx context.Context, advertiserName string) error {
+	if err := validateZeroParam(advertiserName); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.getRepoAdvertiser(advertiserName); err == nil {
+		return entities.ErrAdvertiserExist(advertiserName)
+	}
+	advertiser := &Advertiser{
+		Model:      r.newModel(),
+		Advertiser: entities.Advertiser{Name: advertiserName},
+	}
+	r.advertisers[advertiser.ID] = advertiser
+	return nil
+}
+
+func (r *MemRepo) GetAdvertisers(ctx context.Context) (advertisers []entities.Advertiser, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	as := make([]*Advertiser, 0, len(r.advertisers))
+	for _, a := range r.advertisers {
+		as = append(as, a)
+	}
+	sort.Slice(as, func(i, j int) bool { return as[i].ID < as[j].ID })
+	for _, a := range as {
+		advertisers = append(advertisers, a.Advertiser)
+	}
+	return
+}
+
+func (r *MemRepo) SetAdvertiserPaused(ctx context.Context, advertiserName string, paused bool) error {
+	if err := validateZeroParam(advertiserName); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	advertiser, err := r.getRepoAdvertiser(advertiserName)
+	if err != nil {
+		return err
+	}
+	// pause or resume all advertiser campaigns.
+	for _, campaign := range r.campaigns {
+		if campaign.AdvertiserID == advertiser.ID {
+			campaign.Paused = paused
+		}
+	}
+	return nil
+}
+
+func (r *MemRepo) GetAdvertiserActions(ctx context.Context, advertiserName string) (actions map[entities.Group]entities.Action, err error) {
+	if err := validateZeroParam(advertiserName); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	advertiser, err := r.getRepoAdvertiser(advertiserName)
+	if err != nil {
+		return nil, err
+	}
+	return r.sumActions(func(banner *Banner) bool {
+		campaign, ok := r.campaigns[banner.CampaignID]
+		return ok && campaign.AdvertiserID == advertiser.ID
+	}), nil
+}
+
+func (r *MemRepo) AddCampaign(ctx context.Context, advertiserName, campaignName string) error {
+	if err := validateZeroParam(advertiserName, campaignName); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	advertiser, err := r.getRepoAdvertiser(advertiserName)
+	if err != nil {
+		return err
+	}
+	if _, err := r.getRepoCampaign(campaignName); err == nil {
+		return entities.ErrCampaignExist(campaignName)
+	}
+	campaign := &Campaign{
+		Model:        r.newModel(),
+		AdvertiserID: advertiser.ID,
+		Campaign:     entities.Campaign{Name: campaignName},
+	}
+	r.campaigns[campaign.ID] = campaign
+	return nil
+}
+
+func (r *MemRepo) GetCampaigns(ctx context.Context, advertiserName string) (campaigns []entities.Campaign, err error) {
+	if err := validateZeroParam(advertiserName); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	advertiser, err := r.getRepoAdvertiser(advertiserName)
+	if err != nil {
+		return nil, err
+	}
+	var cs []*Campaign
+	for _, c := range r.campaigns {
+		if c.AdvertiserID == advertiser.ID {
+			cs = append(cs, c)
+		}
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].ID < cs[j].ID })
+	for _, c := range cs {
+		campaigns = append(campaigns, c.Campaign)
+	}
+	return
+}
+
+func (r *MemRepo) AddBannerToCampaign(ctx context.Context, campaignName string, bannerInnerID uint) error {
+	if err := validateZeroParam(campaignName, bannerInnerID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	campaign, err := r.getRepoCampaign(campaignName)
+	if err != nil {
+		return err
+	}
+	banners := r.getRepoBannersByInnerID(bannerInnerID)
+	switch {
+	case len(banners) == 0:
+		return entities.ErrBannerIDNotFound(bannerInnerID)
+	case len(banners) > 1:
+		return entities.ErrBannerIDAmbiguous(bannerInnerID)
+	}
+	banners[0].CampaignID = campaign.ID
+	return nil
+}
+
+func (r *MemRepo) SetCampaignPaused(ctx context.Context, campaignName string, paused bool) error {
+	if err := validateZeroParam(campaignName); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	campaign, err := r.getRepoCampaign(campaignName)
+	if err != nil {
+		return err
+	}
+	campaign.Paused = paused
+	return nil
+}
+
+func (r *MemRepo) GetCampaignActions(ctx context.Context, campaignName string) (actions map[entities.Group]entities.Action, err error) {
+	if err := validateZeroParam(campaignName); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	campaign, err := r.getRepoCampaign(campaignName)
+	if err != nil {
+		return nil, err
+	}
+	return r.sumActions(func(banner *Banner) bool {
+		return banner.CampaignID == campaign.ID
+	}), nil
+}
+
+func (r *MemRepo) GetPausedBanners(ctx context.Context) (banners []entities.Banner, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var bs []*Banner
+	for _, banner := range r.banners {
+		if campaign, ok := r.campaigns[banner.CampaignID]; ok && campaign.Paused {
+			bs = append(bs, banner)
+		}
+	}
+	sort.Slice(bs, func(i, j int) bool { return bs[i].ID < bs[j].ID })
+	for _, banner := range bs {
+		banners = append(banners, banner.Banner)
+	}
+	return
+}
+
+func (r *MemRepo) getRepoAdvertiser(advertiserName string) (*Advertiser, error) {
+	for _, advertiser := range r.advertisers {
+		if advertiser.Name == advertiserName {
+			return advertiser, nil
+		}
+	}
+	return nil, entities.ErrAdvertiserNotFound(advertiserName)
+}
+
+func (r *MemRepo) getRepoCampaign(campaignName string) (*Campaign, error) {
+	for _, campaign := range r.campaigns {
+		if campaign.Name == campaignName {
+			return campaign, nil
+		}
+	}
+	return nil, entities.ErrCampaignNotFound(campaignName)
+}
+
+// sumActions sums counters of events of matched banners for each user group.
+func (r *MemRepo) sumActions(match func(banner *Banner) bool) (actions map[entities.Group]entities.Action) {
+	actions = make(map[entities.Group]entities.Action)
+	for _, group := range r.groups {
+		actions[group.Group] = entities.Action{}
+	}
+	for key, event := range r.events {
+		bannerSlot, ok := r.bannerSlots[key.bannerSlotID]
+		if !ok {
+			continue
+		}
+		banner, ok := r.banners[bannerSlot.BannerID]
+		group, gok := r.groups[key.groupID]
+		if !ok || !gok || !match(banner) {
+			continue
+		}
+		sum := actions[group.Group]
+		sum.Clicks += event.Clicks
+		sum.Shows += event.Shows
+		sum.Views += event.Views
+		actions[group.Group] = sum
+	}
+	return
+}
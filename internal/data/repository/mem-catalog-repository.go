@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.BannerCatalogRepository = (*MemRepo)(nil)
+
+func (r *MemRepo) AddCatalogBanner(ctx context.Context, bannerInnerID uint, bannerDescription string, creative entities.Creative) error {
+	if err := validateZeroParam(bannerInnerID, bannerDescription); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.getRepoCatalogBanner(bannerInnerID); err == nil {
+		return entities.ErrCatalogBannerExist(bannerInnerID)
+	}
+	// banner can be already added to slots.
+	banner := r.findBanner(bannerInnerID, bannerDescription)
+	if banner == nil {
+		banner = &Banner{
+			Model: r.newModel(),
+			Banner: entities.Banner{
+				InnerID:     bannerInnerID,
+				Description: bannerDescription,
+				Creative:    creative,
+			},
+		}
+		r.banners[banner.ID] = banner
+	}
+	banner.Catalog = true
+	return nil
+}
+
+func (r *MemRepo) GetCatalogBanners(ctx context.Context) (banners []entities.Banner, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var bs []*Banner
+	for _, banner := range r.banners {
+		if banner.Catalog {
+			bs = append(bs, banner)
+		}
+	}
+	sort.Slice(bs, func(i, j int) bool { return bs[i].InnerID < bs[j].InnerID })
+	for _, banner := range bs {
+		banners = append(banners, banner.Banner)
+	}
+	return
+}
+
+// DeleteCatalogBanner deletes the detached catalog banner, banners attached to slots are kept with their stats.
+func (r *MemRepo) DeleteCatalogBanner(ctx context.Context, bannerInnerID uint) error {
+	if err := validateZeroParam(bannerInnerID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	banner, err := r.getRepoCatalogBanner(bannerInnerID)
+	if err != nil {
+		return err
+	}
+	if len(r.getRepoBannerSlots(banner.ID)) != 0 {
+		return entities.ErrCatalogBannerAttached(bannerInnerID)
+	}
+	delete(r.banners, banner.ID)
+	return nil
+}
+
+func (r *MemRepo) AttachBanner(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) error {
+	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	slot, err := r.getRepoSlot(pageURL, slotInnerID)
+	if err != nil {
+		return err
+	}
+	banner, err := r.getRepoCatalogBanner(bannerInnerID)
+	if err != nil {
+		return err
+	}
+	return r.createBannerSlot(pageURL, slot, banner)
+}
+
+func (r *MemRepo) GetBannerActions(ctx context.Context, bannerInnerID uint) (actions map[entities.Group]entities.Action, err error) {
+	if err := validateZeroParam(bannerInnerID); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	catalogBanner, err := r.getRepoCatalogBanner(bannerInnerID)
+	if err != nil {
+		return nil, err
+	}
+	// slot banners can have the same inner id.
+	return r.sumActions(func(banner *Banner) bool {
+		return banner.ID == catalogBanner.ID
+	}), nil
+}
+
+func (r *MemRepo) getRepoCatalogBanner(bannerInnerID uint) (*Banner, error) {
+	for _, banner := range r.getRepoBannersByInnerID(bannerInnerID) {
+		if banner.Catalog {
+			return banner, nil
+		}
+	}
+	return nil, entities.ErrCatalogBannerNotFound(bannerInnerID)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.HistoryRepository = (*MemRepo)(nil)
+
+func (r *MemRepo) GetBuckets(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint, from, to time.Time) (buckets []entities.StatBucket, err error) {
+	if err := validateZeroParam(pageURL); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var rows []*BannerEventBucket
+	for _, bucket := range r.buckets {
+		if !bucket.Hour.Before(from) && bucket.Hour.Before(to) {
+			rows = append(rows, bucket)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].Hour.Equal(rows[j].Hour) {
+			return rows[i].Hour.Before(rows[j].Hour)
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	for _, row := range rows {
+		bannerSlot, ok := r.bannerSlots[row.BannerSlotID]
+		if !ok {
+			continue
+		}
+		slot, sok := r.slots[bannerSlot.SlotID]
+		banner, bok := r.banners[bannerSlot.BannerID]
+		group, gok := r.groups[row.GroupID]
+		if !sok || !bok || !gok {
+			continue
+		}
+		if page, ok := r.pages[slot.PageID]; !ok || page.URL != pageURL {
+			continue
+		}
+		if (slotInnerID != 0 && slot.InnerID != slotInnerID) || (bannerInnerID != 0 && banner.InnerID != bannerInnerID) {
+			continue
+		}
+		buckets = append(buckets, entities.StatBucket{
+			Start:            row.Hour,
+			SlotID:           slot.InnerID,
+			BannerID:         banner.InnerID,
+			GroupDescription: group.Description,
+			Action:           row.Action,
+		})
+	}
+	return
+}
+
+func (r *MemRepo) DeleteBucketsBefore(ctx context.Context, t time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, bucket := range r.buckets {
+		if bucket.Hour.Before(t) {
+			delete(r.buckets, key)
+		}
+	}
+	return nil
+}
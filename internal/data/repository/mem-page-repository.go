@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func (r *MemRepo) AddPage(ctx context.Context, pageURL string, settings entities.PageSettings) error {
+	if err := validateZeroParam(pageURL); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.getRepoPage(pageURL); err == nil {
+		return entities.ErrPageExist(pageURL)
+	}
+	page := &Page{
+		Model: r.newModel(),
+		Page: entities.Page{
+			URL:       pageURL,
+			Algorithm: settings.Algorithm,
+		},
+		AllowedCategories: strings.Join(settings.AllowedCategories, listSeparator),
+	}
+	r.pages[page.ID] = page
+	return nil
+}
+
+func (r *MemRepo) RenamePage(ctx context.Context, pageURL, newPageURL string) error {
+	if err := validateZeroParam(pageURL, newPageURL); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	page, err := r.getRepoPage(pageURL)
+	if err != nil {
+		return err
+	}
+	if other, err := r.getRepoPage(newPageURL); err == nil && other.ID != page.ID {
+		return entities.ErrPageExist(newPageURL)
+	}
+	page.URL = newPageURL
+	return nil
+}
+
+func (r *MemRepo) SetPageSettings(ctx context.Context, pageURL string, settings entities.PageSettings) error {
+	if err := validateZeroParam(pageURL); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	page, err := r.getRepoPage(pageURL)
+	if err != nil {
+		return err
+	}
+	page.Algorithm = settings.Algorithm
+	page.AllowedCategories = strings.Join(settings.AllowedCategories, listSeparator)
+	page.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *MemRepo) GetPageSettings(ctx context.Context) (settings map[string]entities.PageSettings, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	settings = make(map[string]entities.PageSettings, len(r.pages))
+	for _, page := range r.pages {
+		settings[page.URL] = entities.PageSettings{
+			Algorithm:         page.Algorithm,
+			AllowedCategories: splitList(page.AllowedCategories),
+		}
+	}
+	return
+}
+
+func (r *MemRepo) DeletePage(ctx context.Context, pageURL string) error {
+	if err := validateZeroParam(pageURL); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	page, err := r.getRepoPage(pageURL)
+	if err != nil {
+		return err
+	}
+	// delete slots with banners and events.
+	if err := r.deleteAllSlots(pageURL); err != nil {
+		return err
+	}
+	delete(r.pages, page.ID)
+	return nil
+}
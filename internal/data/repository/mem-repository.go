@@ -0,0 +1,665 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/shipa988/banner_rotator/internal/data/logger"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.BannerRepository = (*MemRepo)(nil)
+var _ entities.SlotRepository = (*MemRepo)(nil)
+var _ entities.PageRepository = (*MemRepo)(nil)
+var _ entities.ActionRepository = (*MemRepo)(nil)
+var _ entities.GroupRepository = (*MemRepo)(nil)
+
+type eventKey struct {
+	bannerSlotID uint
+	groupID      uint
+}
+
+type bucketKey struct {
+	bannerSlotID uint
+	groupID      uint
+	hour         int64
+}
+
+// MemRepo keeps the same tables as PGRepo in memory, it is not persisted between runs.
+// Missing and duplicated records are reported with the same domain errors as PGRepo does.
+type MemRepo struct {
+	mu          sync.RWMutex
+	logger      logger.Logger
+	lastID      uint
+	pages       map[uint]*Page
+	slots       map[uint]*Slot
+	banners     map[uint]*Banner
+	bannerSlots map[uint]*BannerSlot
+	groups      map[uint]*Group
+	events      map[eventKey]*BannerEvent
+	buckets     map[bucketKey]*BannerEventBucket
+	advertisers map[uint]*Advertiser
+	campaigns   map[uint]*Campaign
+	// targetings and tags by banner inner id.
+	targetings map[uint]*BannerTargeting
+	tags       map[uint]*BannerTags
+}
+
+func NewMemRepo(logger logger.Logger) *MemRepo {
+	r := &MemRepo{logger: logger}
+	r.reset()
+	return r
+}
+
+func (r *MemRepo) CreateDB() {
+	// in-memory db has no schema, data is seeded by the fixture.
+	r.logger.Log(context.Background(), "db creation complete")
+}
+
+func (r *MemRepo) DeleteDB() {
+	r.logger.Log(context.Background(), "db deleting...")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reset()
+	r.logger.Log(context.Background(), "db deleting complete...")
+}
+
+func (r *MemRepo) reset() {
+	r.pages = make(map[uint]*Page)
+	r.slots = make(map[uint]*Slot)
+	r.banners = make(map[uint]*Banner)
+	r.bannerSlots = make(map[uint]*BannerSlot)
+	r.groups = make(map[uint]*Group)
+	r.events = make(map[eventKey]*BannerEvent)
+	r.buckets = make(map[bucketKey]*BannerEventBucket)
+	r.advertisers = make(map[uint]*Advertiser)
+	r.campaigns = make(map[uint]*Campaign)
+	r.targetings = make(map[uint]*BannerTargeting)
+	r.tags = make(map[uint]*BannerTags)
+}
+
+func (r *MemRepo) GetPages(ctx context.Context) (pages []entities.Page, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, page := range r.getRepoPages() {
+		pages = append(pages, page.Page)
+	}
+	return
+}
+
+func (r *MemRepo) GetSlotsByPageURL(ctx context.Context, pageURL string) (slots []entities.Slot, err error) {
+	if err := validateZeroParam(pageURL); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sls, err := r.getRepoSlots(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range sls {
+		slots = append(slots, slot.Slot)
+	}
+	return
+}
+
+func (r *MemRepo) GetBannersBySlotID(ctx context.Context, pageURL string, slotInnerID uint) (banners []entities.Banner, err error) {
+	if err := validateZeroParam(pageURL, slotInnerID); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	repoBanners, err := r.getRepoBanners(pageURL, slotInnerID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, repoBanner := range repoBanners {
+		banners = append(banners, repoBanner.Banner)
+	}
+	return
+}
+
+func (r *MemRepo) GetSlot(ctx context.Context, pageURL string, slotInnerID uint) (slot *entities.Slot, err error) {
+	if err := validateZeroParam(pageURL, slotInnerID); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	repoSlot, err := r.getRepoSlot(pageURL, slotInnerID)
+	if err != nil {
+		return nil, err
+	}
+	s := repoSlot.Slot
+	return &s, nil
+}
+
+func (r *MemRepo) GetBanner(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) (banner *entities.Banner, err error) {
+	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	repoBanners, err := r.getRepoBanners(pageURL, slotInnerID, bannerInnerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(repoBanners) == 0 {
+		return nil, entities.ErrBannerNotFound(slotInnerID, bannerInnerID, pageURL)
+	}
+	b := repoBanners[0].Banner
+	return &b, nil
+}
+
+func (r *MemRepo) GetGroup(ctx context.Context, userAge uint, userSex string) (group *entities.Group, err error) {
+	if err := validateZeroParam(userAge, userSex); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, g := range r.getRepoGroups() {
+		if g.MinAge < userAge && g.MaxAge >= userAge && g.Sex == userSex {
+			found := g.Group
+			return &found, nil
+		}
+	}
+	return nil, entities.ErrGroupForUserNotFound(userAge, userSex)
+}
+
+func (r *MemRepo) GetGroups(ctx context.Context) (groups []entities.Group, defaultGroupDescription string, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, g := range r.getRepoGroups() {
+		if g.IsDefault {
+			defaultGroupDescription = g.Description
+		}
+		groups = append(groups, g.Group)
+	}
+	return
+}
+
+func (r *MemRepo) GetGroupRules(ctx context.Context) (rules map[string][]entities.Rule, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rules = make(map[string][]entities.Rule)
+	for _, group := range r.getRepoGroups() {
+		for _, rule := range group.Rules {
+			rules[group.Description] = append(rules[group.Description], rule.Rule)
+		}
+	}
+	return
+}
+
+func (r *MemRepo) AddGroup(ctx context.Context, group entities.Group, rules []entities.Rule) error {
+	if err := validateZeroParam(group.Description); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.createGroup(group, rules)
+}
+
+func (r *MemRepo) createGroup(group entities.Group, rules []entities.Rule) error {
+	if r.groupExists(0, group) {
+		return entities.ErrGroupExist(group.Description)
+	}
+	repoGroup := &Group{Model: r.newModel(), Group: group}
+	r.setGroupRules(repoGroup, rules)
+	r.groups[repoGroup.ID] = repoGroup
+	return nil
+}
+
+func (r *MemRepo) UpdateGroup(ctx context.Context, groupDescription string, group entities.Group, rules []entities.Rule) error {
+	if err := validateZeroParam(groupDescription, group.Description); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	repoGroup, err := r.getRepoGroupByDescription(groupDescription)
+	if err != nil {
+		return err
+	}
+	if r.groupExists(repoGroup.ID, group) {
+		return entities.ErrGroupExist(group.Description)
+	}
+	// replace group rules.
+	repoGroup.Group = group
+	repoGroup.UpdatedAt = time.Now()
+	r.setGroupRules(repoGroup, rules)
+	return nil
+}
+
+func (r *MemRepo) DeleteGroup(ctx context.Context, groupDescription string) error {
+	if err := validateZeroParam(groupDescription); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	group, err := r.getRepoGroupByDescription(groupDescription)
+	if err != nil {
+		return err
+	}
+	// events and buckets are the group history.
+	for key := range r.events {
+		if key.groupID == group.ID {
+			return entities.ErrGroupHasEvents(groupDescription)
+		}
+	}
+	for key := range r.buckets {
+		if key.groupID == group.ID {
+			return entities.ErrGroupHasEvents(groupDescription)
+		}
+	}
+	// delete group with rules.
+	delete(r.groups, group.ID)
+	return nil
+}
+
+func (r *MemRepo) GetActions(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) (actions map[entities.Group]entities.Action, err error) {
+	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	bannerSlot, err := r.getRepoBannerSlot(pageURL, slotInnerID, bannerInnerID)
+	if err != nil {
+		return nil, err
+	}
+	actions = make(map[entities.Group]entities.Action)
+	// to fill in groups-actions
+	for _, group := range r.getRepoGroups() {
+		actions[group.Group] = entities.Action{}
+		if event, ok := r.events[eventKey{bannerSlotID: bannerSlot.ID, groupID: group.ID}]; ok {
+			actions[group.Group] = event.Action
+		}
+	}
+	return
+}
+
+func (r *MemRepo) AddSlot(ctx context.Context, pageURL string, slotInnerID uint, slotDescription string, viewableTry bool, width, height uint) (err error) {
+	if err := validateZeroParam(pageURL, slotInnerID, slotDescription); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// get page or create.
+	page, err := r.getRepoPage(pageURL)
+	if err != nil {
+		page = &Page{Model: r.newModel(), Page: entities.Page{URL: pageURL}}
+		r.pages[page.ID] = page
+	}
+	if _, err := r.getRepoSlot(pageURL, slotInnerID); err == nil {
+		return entities.ErrSlotExist(slotInnerID, pageURL)
+	}
+	slot := &Slot{
+		Model:  r.newModel(),
+		PageID: page.ID,
+		Slot: entities.Slot{
+			InnerID:     slotInnerID,
+			Description: slotDescription,
+			ViewableTry: viewableTry,
+			Width:       width,
+			Height:      height,
+		},
+	}
+	r.slots[slot.ID] = slot
+	return nil
+}
+
+func (r *MemRepo) AddBannerToSlot(ctx context.Context, pageURL string, slotInnerID uint, bannerInnerID uint, bannerDescription string, creative entities.Creative) (err error) {
+	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID, bannerDescription); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	slot, err := r.getRepoSlot(pageURL, slotInnerID)
+	if err != nil {
+		return err
+	}
+	// get banner if exist, if not-create with creative.
+	banner := r.findBanner(bannerInnerID, bannerDescription)
+	if banner == nil {
+		banner = &Banner{
+			Model: r.newModel(),
+			Banner: entities.Banner{
+				InnerID:     bannerInnerID,
+				Description: bannerDescription,
+				Creative:    creative,
+			},
+		}
+		r.banners[banner.ID] = banner
+	}
+	// the banner is shared by slots, so its creative isn't replaced silently.
+	if banner.Creative != creative {
+		return entities.ErrBannerCreativeExist(bannerInnerID, bannerDescription)
+	}
+	return r.createBannerSlot(pageURL, slot, banner)
+}
+
+func (r *MemRepo) createBannerSlot(pageURL string, slot *Slot, banner *Banner) error {
+	for _, bannerSlot := range r.bannerSlots {
+		if bannerSlot.BannerID == banner.ID && bannerSlot.SlotID == slot.ID {
+			return entities.ErrBannerExist(slot.InnerID, banner.InnerID, pageURL, banner.Description)
+		}
+	}
+	bannerSlot := &BannerSlot{Model: r.newModel(), BannerID: banner.ID, SlotID: slot.ID}
+	r.bannerSlots[bannerSlot.ID] = bannerSlot
+	return nil
+}
+
+func (r *MemRepo) UpdateSlot(ctx context.Context, pageURL string, slotInnerID uint, slotDescription string, viewableTry bool, width, height uint) error {
+	if err := validateZeroParam(pageURL, slotInnerID, slotDescription); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	slot, err := r.getRepoSlot(pageURL, slotInnerID)
+	if err != nil {
+		return err
+	}
+	slot.Description = slotDescription
+	slot.ViewableTry = viewableTry
+	slot.Width = width
+	slot.Height = height
+	slot.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *MemRepo) UpdateBanner(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint, bannerDescription string, creative entities.Creative) error {
+	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID, bannerDescription); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	banners, err := r.getRepoBanners(pageURL, slotInnerID, bannerInnerID)
+	if err != nil {
+		return err
+	}
+	if len(banners) == 0 {
+		return entities.ErrBannerNotFound(slotInnerID, bannerInnerID, pageURL)
+	}
+	banner := banners[0]
+	if other := r.findBanner(bannerInnerID, bannerDescription); other != nil && other.ID != banner.ID {
+		return entities.ErrBannerExist(slotInnerID, bannerInnerID, pageURL, bannerDescription)
+	}
+	banner.Description = bannerDescription
+	banner.Creative = creative
+	banner.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *MemRepo) DeleteSlot(ctx context.Context, pageURL string, slotInnerID uint) error {
+	if err := validateZeroParam(pageURL, slotInnerID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deleteSlot(pageURL, slotInnerID)
+}
+
+func (r *MemRepo) deleteSlot(pageURL string, slotInnerID uint) error {
+	slot, err := r.getRepoSlot(pageURL, slotInnerID)
+	if err != nil {
+		return err
+	}
+	// delete banners from slot.
+	if err := r.deleteAllBannersFormSlot(pageURL, slotInnerID); err != nil {
+		return err
+	}
+	delete(r.slots, slot.ID)
+	return nil
+}
+
+func (r *MemRepo) DeleteBannerFromSlot(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) error {
+	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deleteBannerFromSlot(pageURL, slotInnerID, bannerInnerID)
+}
+
+func (r *MemRepo) deleteBannerFromSlot(pageURL string, slotInnerID, bannerInnerID uint) error {
+	banners, err := r.getRepoBanners(pageURL, slotInnerID, bannerInnerID)
+	if err != nil {
+		return err
+	}
+	for _, banner := range banners {
+		bannerSlot, err := r.getRepoBannerSlot(pageURL, slotInnerID, bannerInnerID)
+		if err != nil {
+			return err
+		}
+		r.deleteBannerSlot(bannerSlot)
+		//delete banner if it doesn't contain at least one relation to slot
+		if len(r.getRepoBannerSlots(banner.ID)) == 0 && !banner.Catalog {
+			delete(r.banners, banner.ID)
+		}
+	}
+	return nil
+}
+
+// deleteBannerSlot deletes the relation with its events and event buckets.
+func (r *MemRepo) deleteBannerSlot(bannerSlot *BannerSlot) {
+	for key := range r.events {
+		if key.bannerSlotID == bannerSlot.ID {
+			delete(r.events, key)
+		}
+	}
+	for key := range r.buckets {
+		if key.bannerSlotID == bannerSlot.ID {
+			delete(r.buckets, key)
+		}
+	}
+	delete(r.bannerSlots, bannerSlot.ID)
+}
+
+func (r *MemRepo) DeleteAllSlots(ctx context.Context, pageURL string) error {
+	if err := validateZeroParam(pageURL); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deleteAllSlots(pageURL)
+}
+
+func (r *MemRepo) deleteAllSlots(pageURL string) error {
+	slots, err := r.getRepoSlots(pageURL)
+	if err != nil {
+		return err
+	}
+	for _, slot := range slots {
+		if err := r.deleteSlot(pageURL, slot.InnerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemRepo) DeleteAllBannersFormSlot(ctx context.Context, pageURL string, slotInnerID uint) error {
+	if err := validateZeroParam(pageURL, slotInnerID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deleteAllBannersFormSlot(pageURL, slotInnerID)
+}
+
+func (r *MemRepo) deleteAllBannersFormSlot(pageURL string, slotInnerID uint) error {
+	banners, err := r.getRepoBanners(pageURL, slotInnerID, 0)
+	if err != nil {
+		return err
+	}
+	//delete loop
+	for _, banner := range banners {
+		if err := r.deleteBannerFromSlot(pageURL, slotInnerID, banner.InnerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newModel returns the model with the next primary key, the caller holds the write lock.
+func (r *MemRepo) newModel() gorm.Model {
+	r.lastID++
+	now := time.Now()
+	return gorm.Model{ID: r.lastID, CreatedAt: now, UpdatedAt: now}
+}
+
+func (r *MemRepo) getRepoPage(pageURL string) (*Page, error) {
+	for _, page := range r.pages {
+		if page.URL == pageURL {
+			return page, nil
+		}
+	}
+	return nil, entities.ErrPageNotFound(pageURL)
+}
+
+func (r *MemRepo) getRepoPages() []*Page {
+	ps := make([]*Page, 0, len(r.pages))
+	for _, page := range r.pages {
+		ps = append(ps, page)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].ID < ps[j].ID })
+	return ps
+}
+
+func (r *MemRepo) getRepoSlot(pageURL string, slotInnerID uint) (*Slot, error) {
+	page, err := r.getRepoPage(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range r.slots {
+		if slot.PageID == page.ID && slot.InnerID == slotInnerID {
+			return slot, nil
+		}
+	}
+	return nil, entities.ErrSlotNotFound(slotInnerID, pageURL)
+}
+
+func (r *MemRepo) getRepoSlots(pageURL string) ([]*Slot, error) {
+	page, err := r.getRepoPage(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	var slots []*Slot
+	for _, slot := range r.slots {
+		if slot.PageID == page.ID {
+			slots = append(slots, slot)
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].ID < slots[j].ID })
+	return slots, nil
+}
+
+// getRepoBanners returns banners of the slot, zero banner inner id means any.
+func (r *MemRepo) getRepoBanners(pageURL string, slotInnerID uint, bannerInnerID uint) (banners []*Banner, err error) {
+	slot, err := r.getRepoSlot(pageURL, slotInnerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, bannerSlot := range r.sortedBannerSlots() {
+		if bannerSlot.SlotID != slot.ID {
+			continue
+		}
+		banner, ok := r.banners[bannerSlot.BannerID]
+		if ok && (bannerInnerID == 0 || banner.InnerID == bannerInnerID) {
+			banners = append(banners, banner)
+		}
+	}
+	return
+}
+
+func (r *MemRepo) getRepoBannerSlot(pageURL string, slotInnerID uint, bannerInnerID uint) (*BannerSlot, error) {
+	slot, err := r.getRepoSlot(pageURL, slotInnerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, bannerSlot := range r.sortedBannerSlots() {
+		if bannerSlot.SlotID != slot.ID {
+			continue
+		}
+		if banner, ok := r.banners[bannerSlot.BannerID]; ok && banner.InnerID == bannerInnerID {
+			return bannerSlot, nil
+		}
+	}
+	return nil, entities.ErrBannerNotFound(slotInnerID, bannerInnerID, pageURL)
+}
+
+// getRepoBannerSlots returns slot relations of the banner.
+func (r *MemRepo) getRepoBannerSlots(bannerID uint) (bannerSlots []*BannerSlot) {
+	for _, bannerSlot := range r.sortedBannerSlots() {
+		if bannerSlot.BannerID == bannerID {
+			bannerSlots = append(bannerSlots, bannerSlot)
+		}
+	}
+	return
+}
+
+func (r *MemRepo) sortedBannerSlots() []*BannerSlot {
+	bs := make([]*BannerSlot, 0, len(r.bannerSlots))
+	for _, bannerSlot := range r.bannerSlots {
+		bs = append(bs, bannerSlot)
+	}
+	sort.Slice(bs, func(i, j int) bool { return bs[i].ID < bs[j].ID })
+	return bs
+}
+
+// findBanner returns the banner with the inner id and description or nil.
+func (r *MemRepo) findBanner(bannerInnerID uint, bannerDescription string) *Banner {
+	for _, banner := range r.getRepoBannersByInnerID(bannerInnerID) {
+		if banner.Description == bannerDescription {
+			return banner
+		}
+	}
+	return nil
+}
+
+func (r *MemRepo) getRepoBannersByInnerID(bannerInnerID uint) (banners []*Banner) {
+	for _, banner := range r.banners {
+		if banner.InnerID == bannerInnerID {
+			banners = append(banners, banner)
+		}
+	}
+	sort.Slice(banners, func(i, j int) bool { return banners[i].ID < banners[j].ID })
+	return
+}
+
+func (r *MemRepo) getRepoGroupByDescription(groupDescription string) (*Group, error) {
+	for _, group := range r.getRepoGroups() {
+		if group.Description == groupDescription {
+			return group, nil
+		}
+	}
+	return nil, entities.ErrGroupNotFound(groupDescription)
+}
+
+func (r *MemRepo) getRepoGroups() []*Group {
+	gs := make([]*Group, 0, len(r.groups))
+	for _, group := range r.groups {
+		gs = append(gs, group)
+	}
+	sort.Slice(gs, func(i, j int) bool { return gs[i].ID < gs[j].ID })
+	return gs
+}
+
+// groupExists reports whether other group than the group with the id has the same unique fields.
+func (r *MemRepo) groupExists(groupID uint, group entities.Group) bool {
+	for _, other := range r.groups {
+		if other.ID != groupID && other.Description == group.Description && other.Sex == group.Sex &&
+			other.MinAge == group.MinAge && other.MaxAge == group.MaxAge {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemRepo) setGroupRules(group *Group, rules []entities.Rule) {
+	group.Rules = toRepoRules(rules)
+	for _, rule := range group.Rules {
+		rule.Model = r.newModel()
+		rule.GroupID = group.ID
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.TargetingRepository = (*MemRepo)(nil)
+var _ entities.TagsRepository = (*MemRepo)(nil)
+
+func (r *MemRepo) SetBannerTargeting(ctx context.Context, bannerInnerID uint, targeting entities.Targeting) error {
+	if err := validateZeroParam(bannerInnerID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// check banner exists.
+	if len(r.getRepoBannersByInnerID(bannerInnerID)) == 0 {
+		return entities.ErrBannerIDNotFound(bannerInnerID)
+	}
+	repoTargeting, ok := r.targetings[bannerInnerID]
+	if !ok {
+		repoTargeting = &BannerTargeting{Model: r.newModel(), BannerInnerID: bannerInnerID}
+		r.targetings[bannerInnerID] = repoTargeting
+	}
+	repoTargeting.MinAge = targeting.MinAge
+	repoTargeting.MaxAge = targeting.MaxAge
+	repoTargeting.Sex = targeting.Sex
+	repoTargeting.IncludeGroups = strings.Join(targeting.IncludeGroups, listSeparator)
+	repoTargeting.ExcludeGroups = strings.Join(targeting.ExcludeGroups, listSeparator)
+	return nil
+}
+
+func (r *MemRepo) DeleteBannerTargeting(ctx context.Context, bannerInnerID uint) error {
+	if err := validateZeroParam(bannerInnerID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.targetings, bannerInnerID)
+	return nil
+}
+
+func (r *MemRepo) GetTargetings(ctx context.Context) (targetings map[uint]entities.Targeting, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	targetings = make(map[uint]entities.Targeting, len(r.targetings))
+	for _, t := range r.targetings {
+		targetings[t.BannerInnerID] = entities.Targeting{
+			IncludeGroups: splitList(t.IncludeGroups),
+			ExcludeGroups: splitList(t.ExcludeGroups),
+			MinAge:        t.MinAge,
+			MaxAge:        t.MaxAge,
+			Sex:           t.Sex,
+		}
+	}
+	return
+}
+
+func (r *MemRepo) SetBannerTags(ctx context.Context, bannerInnerID uint, tags entities.Tags) error {
+	if err := validateZeroParam(bannerInnerID); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// check banner exists.
+	if len(r.getRepoBannersByInnerID(bannerInnerID)) == 0 {
+		return entities.ErrBannerIDNotFound(bannerInnerID)
+	}
+	repoTags, ok := r.tags[bannerInnerID]
+	if !ok {
+		repoTags = &BannerTags{Model: r.newModel(), BannerInnerID: bannerInnerID}
+		r.tags[bannerInnerID] = repoTags
+	}
+	repoTags.Categories = strings.Join(tags.Categories, listSeparator)
+	repoTags.Competitors = strings.Join(tags.Competitors, listSeparator)
+	return nil
+}
+
+func (r *MemRepo) GetTags(ctx context.Context) (tags map[uint]entities.Tags, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tags = make(map[uint]entities.Tags, len(r.tags))
+	for _, t := range r.tags {
+		tags[t.BannerInnerID] = entities.Tags{
+			Categories:  splitList(t.Categories),
+			Competitors: splitList(t.Competitors),
+		}
+	}
+	return
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.PageTreeRepository = (*MemRepo)(nil)
+
+func (r *MemRepo) GetPageTrees(ctx context.Context, pageURL string) (pages []entities.PageTree, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	groups := r.getRepoGroups()
+	bannerSlots := r.sortedBannerSlots()
+	for _, page := range r.getRepoPages() {
+		if pageURL != "" && page.URL != pageURL {
+			continue
+		}
+		slots, err := r.getRepoSlots(page.URL)
+		if err != nil {
+			return nil, err
+		}
+		tree := entities.PageTree{Page: page.Page}
+		for _, slot := range slots {
+			slotTree := entities.SlotTree{Slot: slot.Slot}
+			for _, bannerSlot := range bannerSlots {
+				banner, ok := r.banners[bannerSlot.BannerID]
+				if bannerSlot.SlotID != slot.ID || !ok {
+					continue
+				}
+				actions := make(map[entities.Group]entities.Action, len(groups))
+				for _, group := range groups {
+					actions[group.Group] = entities.Action{}
+					if event, ok := r.events[eventKey{bannerSlotID: bannerSlot.ID, groupID: group.ID}]; ok {
+						actions[group.Group] = event.Action
+					}
+				}
+				slotTree.Banners = append(slotTree.Banners, entities.BannerTree{Banner: banner.Banner, Actions: actions})
+			}
+			tree.Slots = append(tree.Slots, slotTree)
+		}
+		pages = append(pages, tree)
+	}
+	if pageURL != "" && len(pages) == 0 {
+		return nil, entities.ErrPageNotFound(pageURL)
+	}
+	return
+}
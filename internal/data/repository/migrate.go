@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// migrationsDir keeps migrations of each dialect in its subdirectory,
+// files are named <version>_<name>.up.sql and <version>_<name>.down.sql.
+const migrationsDir = "sql/migrations"
+
+//go:embed sql/migrations
+var migrationFiles embed.FS
+
+// migrationLockID is the id of the only row of the lock table
+// and the key of the postgres advisory lock.
+const migrationLockID = 1
+
+// adoptedColumn is the column added to the table after it was created by gorm AutoMigrate.
+type adoptedColumn struct {
+	Table, Column, Definition string
+}
+
+// adoptedColumns are added to databases created by gorm AutoMigrate before the first migration,
+// CREATE TABLE IF NOT EXISTS of the first migration skips these tables.
+var adoptedColumns = []adoptedColumn{
+	{"pages", "algorithm", "varchar(255)"},
+	{"pages", "allowed_categories", "varchar(255)"},
+	{"slots", "viewable_try", "boolean NOT NULL DEFAULT false"},
+	{"slots", "width", "integer"},
+	{"slots", "height", "integer"},
+	{"banners", "campaign_id", "integer"},
+	{"banners", "catalog", "boolean NOT NULL DEFAULT false"},
+	{"banners", "asset_url", "varchar(255)"},
+	{"banners", "width", "integer"},
+	{"banners", "height", "integer"},
+	{"banners", "alt_text", "varchar(255)"},
+	{"banners", "target_url", "varchar(255)"},
+	{"banners", "mime_type", "varchar(255)"},
+	{"groups", "priority", "integer NOT NULL DEFAULT 0"},
+	{"banner_events", "views", "integer"},
+}
+
+const ErrMigrationFile = "migration file %v is invalid"
+
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   uint
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// SchemaMigration is the applied migration.
+type SchemaMigration struct {
+	Version   uint      `gorm:"PRIMARY_KEY; AUTO_INCREMENT:false"`
+	Name      string    `gorm:"NOT NULL"`
+	AppliedAt time.Time `gorm:"NOT NULL"`
+}
+
+// SchemaMigrationLock row is updated in the migration transaction,
+// so concurrent migrations wait until it is committed or rolled back.
+type SchemaMigrationLock struct {
+	ID       uint `gorm:"PRIMARY_KEY; AUTO_INCREMENT:false"`
+	LockedAt time.Time
+}
+
+// MigrateUp applies all not applied migrations in one transaction.
+func (r *PGRepo) MigrateUp(ctx context.Context) (applied []Migration, err error) {
+	err = r.migrate(ctx, func(tx *gorm.DB, migrations []Migration, done map[uint]SchemaMigration) error {
+		for _, migration := range migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if migration.Version == migrations[0].Version {
+				if err := adoptColumns(tx); err != nil {
+					return err
+				}
+			}
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return fmt.Errorf("migration %v_%v: %w", migration.Version, migration.Name, err)
+			}
+			if err := tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error; err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// MigrateDown reverts the steps last applied migrations in one transaction.
+func (r *PGRepo) MigrateDown(ctx context.Context, steps int) (reverted []Migration, err error) {
+	err = r.migrate(ctx, func(tx *gorm.DB, migrations []Migration, done map[uint]SchemaMigration) error {
+		for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return fmt.Errorf("migration %v_%v: %w", migration.Version, migration.Name, err)
+			}
+			if err := tx.Where("version = ?", migration.Version).Delete(&SchemaMigration{}).Error; err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reverted, nil
+}
+
+// GetMigrationStatus returns all migrations of the dialect sorted by version.
+func (r *PGRepo) GetMigrationStatus(ctx context.Context) (statuses []MigrationStatus, err error) {
+	err = r.migrate(ctx, func(tx *gorm.DB, migrations []Migration, done map[uint]SchemaMigration) error {
+		for _, migration := range migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if applied, ok := done[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = applied.AppliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// migrate calls the action with migrations of the dialect and applied migrations under the migration lock.
+func (r *PGRepo) migrate(ctx context.Context, action func(tx *gorm.DB, migrations []Migration, done map[uint]SchemaMigration) error) error {
+	r = r.withContext(ctx)
+	migrations, err := loadMigrations(r.db.Dialect().GetName())
+	if err != nil {
+		return err
+	}
+	tx := r.db.BeginTx(ctx, nil)
+	if err := tx.Error; err != nil {
+		return err
+	}
+	if err := r.migrateTx(tx, migrations, action); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func (r *PGRepo) migrateTx(tx *gorm.DB, migrations []Migration, action func(tx *gorm.DB, migrations []Migration, done map[uint]SchemaMigration) error) error {
+	// postgres creates tables of the lock concurrently only under the advisory lock,
+	// sqlite serializes the transactions by the first write.
+	if tx.Dialect().GetName() == "postgres" {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.AutoMigrate(&SchemaMigration{}, &SchemaMigrationLock{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("INSERT INTO schema_migration_locks (id, locked_at) VALUES (?, ?) ON CONFLICT DO NOTHING", migrationLockID, time.Now()).Error; err != nil {
+		return err
+	}
+	// take the lock before reading applied migrations.
+	if err := tx.Model(&SchemaMigrationLock{}).Where("id = ?", migrationLockID).UpdateColumn("locked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	var applied []SchemaMigration
+	if err := tx.Find(&applied).Error; err != nil {
+		return err
+	}
+	done := make(map[uint]SchemaMigration, len(applied))
+	for _, migration := range applied {
+		done[migration.Version] = migration
+	}
+	return action(tx, migrations, done)
+}
+
+// adoptColumns adds columns missing in tables created by gorm AutoMigrate.
+func adoptColumns(tx *gorm.DB) error {
+	dialect := tx.Dialect()
+	for _, column := range adoptedColumns {
+		if !dialect.HasTable(column.Table) || dialect.HasColumn(column.Table, column.Column) {
+			continue
+		}
+		query := fmt.Sprintf("ALTER TABLE %v ADD COLUMN %v %v", dialect.Quote(column.Table), dialect.Quote(column.Column), column.Definition)
+		if err := tx.Exec(query).Error; err != nil {
+			return fmt.Errorf("adopt column %v.%v: %w", column.Table, column.Column, err)
+		}
+	}
+	return nil
+}
+
+// loadMigrations returns migrations of the dialect sorted by version.
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join(migrationsDir, dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrations for dialect %v: %w", dialect, err)
+	}
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		name := strings.TrimSuffix(fileName, ".sql")
+		direction := path.Ext(name)
+		name = strings.TrimSuffix(name, direction)
+		parts := strings.SplitN(name, "_", 2)
+		version, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil || len(parts) != 2 || (direction != ".up" && direction != ".down") {
+			return nil, fmt.Errorf(ErrMigrationFile, fileName)
+		}
+		data, err := migrationFiles.ReadFile(path.Join(dir, fileName))
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: parts[1]}
+			byVersion[uint(version)] = migration
+		}
+		if direction == ".up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf(ErrMigrationFile, fmt.Sprintf("%v_%v", migration.Version, migration.Name))
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
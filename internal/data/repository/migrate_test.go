@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	postgres, err := loadMigrations("postgres")
+	require.NoError(t, err)
+	sqlite, err := loadMigrations("sqlite3")
+	require.NoError(t, err)
+	require.NotEmpty(t, postgres)
+	require.Len(t, sqlite, len(postgres))
+	for i := range postgres {
+		require.Equal(t, postgres[i].Version, sqlite[i].Version)
+		require.Equal(t, postgres[i].Name, sqlite[i].Name)
+	}
+
+	_, err = loadMigrations("mysql")
+	require.Error(t, err)
+}
+
+func TestPGRepo_Migrate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotator")
+	require.NoError(t, err)
+	db, err := gorm.Open("sqlite3", filepath.Join(dir, "rotator.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+	repo := NewPGRepo(db, zaplogger.NewLogger(ioutil.Discard, false), false)
+	ctx := context.Background()
+	migrations, err := loadMigrations("sqlite3")
+	require.NoError(t, err)
+
+	statuses, err := repo.GetMigrationStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, len(migrations))
+	require.False(t, statuses[0].Applied)
+
+	applied, err := repo.MigrateUp(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(migrations))
+	require.True(t, db.HasTable("banner_slots"))
+
+	applied, err = repo.MigrateUp(ctx)
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	statuses, err = repo.GetMigrationStatus(ctx)
+	require.NoError(t, err)
+	require.True(t, statuses[0].Applied)
+	require.False(t, statuses[0].AppliedAt.IsZero())
+
+	reverted, err := repo.MigrateDown(ctx, len(migrations)+1)
+	require.NoError(t, err)
+	require.Len(t, reverted, len(migrations))
+	require.False(t, db.HasTable("banner_slots"))
+
+	applied, err = repo.MigrateUp(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(migrations))
+}
+
+// autoMigrated* are tables as they were created by gorm AutoMigrate before versioned migrations.
+type autoMigratedPage struct {
+	gorm.Model
+	URL string `gorm:"UNIQUE; NOT NULL"`
+}
+
+type autoMigratedSlot struct {
+	gorm.Model
+	PageID      uint `gorm:"UNIQUE_INDEX:innerid_pageid; NOT NULL"`
+	InnerID     uint `gorm:"UNIQUE_INDEX:innerid_pageid; NOT NULL"`
+	Description string
+}
+
+type autoMigratedBanner struct {
+	gorm.Model
+	InnerID     uint   `gorm:"UNIQUE_INDEX:innerid_description; NOT NULL"`
+	Description string `gorm:"UNIQUE_INDEX:innerid_description; NOT NULL"`
+}
+
+type autoMigratedBannerSlot struct {
+	gorm.Model
+	BannerID uint `gorm:"UNIQUE_INDEX:BannerID_SlotID; NOT NULL"`
+	SlotID   uint `gorm:"UNIQUE_INDEX:BannerID_SlotID; NOT NULL"`
+}
+
+type autoMigratedGroup struct {
+	gorm.Model
+	Description string `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
+	Sex         string `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
+	MinAge      uint   `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
+	MaxAge      uint   `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
+}
+
+type autoMigratedBannerEvent struct {
+	gorm.Model
+	Clicks       uint
+	Shows        uint
+	BannerSlotID uint `gorm:"UNIQUE_INDEX:BannerSlotID_GroupID; NOT NULL"`
+	GroupID      uint `gorm:"UNIQUE_INDEX:BannerSlotID_GroupID; NOT NULL"`
+}
+
+func (autoMigratedPage) TableName() string        { return "pages" }
+func (autoMigratedSlot) TableName() string        { return "slots" }
+func (autoMigratedBanner) TableName() string      { return "banners" }
+func (autoMigratedBannerSlot) TableName() string  { return "banner_slots" }
+func (autoMigratedGroup) TableName() string       { return "groups" }
+func (autoMigratedBannerEvent) TableName() string { return "banner_events" }
+
+func TestPGRepo_MigrateAutoMigrated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotator")
+	require.NoError(t, err)
+	db, err := gorm.Open("sqlite3", filepath.Join(dir, "rotator.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+	require.NoError(t, db.AutoMigrate(&autoMigratedPage{}, &autoMigratedSlot{}, &autoMigratedBanner{}, &autoMigratedBannerSlot{}, &autoMigratedGroup{}, &autoMigratedBannerEvent{}).Error)
+	page := &autoMigratedPage{URL: "site.com"}
+	require.NoError(t, db.Create(page).Error)
+	slot := &autoMigratedSlot{PageID: page.ID, InnerID: 1, Description: "top"}
+	require.NoError(t, db.Create(slot).Error)
+	banner := &autoMigratedBanner{InnerID: 1, Description: "sale"}
+	require.NoError(t, db.Create(banner).Error)
+	bannerSlot := &autoMigratedBannerSlot{BannerID: banner.ID, SlotID: slot.ID}
+	require.NoError(t, db.Create(bannerSlot).Error)
+	group := &autoMigratedGroup{Description: "young man", Sex: "man", MinAge: 18, MaxAge: 35}
+	require.NoError(t, db.Create(group).Error)
+	require.NoError(t, db.Create(&autoMigratedBannerEvent{Clicks: 1, Shows: 2, BannerSlotID: bannerSlot.ID, GroupID: group.ID}).Error)
+
+	repo := NewPGRepo(db, zaplogger.NewLogger(ioutil.Discard, false), false)
+	ctx := context.Background()
+	migrations, err := loadMigrations("sqlite3")
+	require.NoError(t, err)
+	applied, err := repo.MigrateUp(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(migrations))
+	for _, column := range adoptedColumns {
+		require.True(t, db.Dialect().HasColumn(column.Table, column.Column), "%v.%v", column.Table, column.Column)
+	}
+
+	require.NoError(t, repo.UpdateSlot(ctx, "site.com", 1, "top", true, 300, 250))
+	slots, err := repo.GetSlotsByPageURL(ctx, "site.com")
+	require.NoError(t, err)
+	require.Equal(t, []entities.Slot{{InnerID: 1, Description: "top", ViewableTry: true, Width: 300, Height: 250}}, slots)
+	actions, err := repo.GetActions(ctx, "site.com", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, entities.Action{Clicks: 1, Shows: 2}, actions[entities.Group{Description: "young man", Sex: "man", MinAge: 18, MaxAge: 35}])
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.ActionBatchRepository = (*PGRepo)(nil)
+
+// upsert statements are supported by postgres and sqlite3.
+const (
+	upsertEventSQL = `INSERT INTO banner_events (created_at, updated_at, banner_slot_id, group_id, clicks, shows, views) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (banner_slot_id, group_id) DO UPDATE SET clicks = banner_events.clicks + excluded.clicks, shows = banner_events.shows + excluded.shows, views = banner_events.views + excluded.views, updated_at = excluded.updated_at`
+	upsertBucketSQL = `INSERT INTO banner_event_buckets (created_at, updated_at, banner_slot_id, group_id, hour, clicks, shows, views) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (banner_slot_id, group_id, hour) DO UPDATE SET clicks = banner_event_buckets.clicks + excluded.clicks, shows = banner_event_buckets.shows + excluded.shows, views = banner_event_buckets.views + excluded.views, updated_at = excluded.updated_at`
+)
+
+type bannerSlotKey struct {
+	pageURL  string
+	slotID   uint
+	bannerID uint
+}
+
+func (r *PGRepo) AddActions(ctx context.Context, increments []entities.ActionIncrement) (skipped []entities.ActionIncrement, err error) {
+	err = r.transaction(ctx, func(tx *PGRepo) error {
+		skipped, err = tx.addActions(increments)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skipped, nil
+}
+
+func (r *PGRepo) addActions(increments []entities.ActionIncrement) (skipped []entities.ActionIncrement, err error) {
+	bannerSlotIDs := make(map[bannerSlotKey]uint)
+	groupIDs := make(map[string]uint)
+	now := time.Now()
+	for _, inc := range increments {
+		if err := validateZeroParam(inc.PageURL, inc.SlotID, inc.BannerID, inc.GroupDescription); err != nil {
+			return nil, err
+		}
+		key := bannerSlotKey{pageURL: inc.PageURL, slotID: inc.SlotID, bannerID: inc.BannerID}
+		bannerSlotID, ok := bannerSlotIDs[key]
+		if !ok {
+			bannerSlot, err := r.getRepoBannerSlot(inc.PageURL, inc.SlotID, inc.BannerID)
+			switch {
+			case errors.Is(err, entities.ErrNotFound):
+			case err != nil:
+				return nil, err
+			default:
+				bannerSlotID = bannerSlot.ID
+			}
+			bannerSlotIDs[key] = bannerSlotID
+		}
+		groupID, ok := groupIDs[inc.GroupDescription]
+		if !ok {
+			group, err := r.getRepoGroupByDescription(inc.GroupDescription)
+			switch {
+			case errors.Is(err, entities.ErrNotFound):
+			case err != nil:
+				return nil, err
+			default:
+				groupID = group.ID
+			}
+			groupIDs[inc.GroupDescription] = groupID
+		}
+		if bannerSlotID == 0 || groupID == 0 {
+			skipped = append(skipped, inc)
+			continue
+		}
+		if err := r.db.Exec(upsertEventSQL, now, now, bannerSlotID, groupID, inc.Clicks, inc.Shows, inc.Views).Error; err != nil {
+			return nil, err
+		}
+		if err := r.db.Exec(upsertBucketSQL, now, now, bannerSlotID, groupID, inc.Hour.UTC().Truncate(time.Hour), inc.Clicks, inc.Shows, inc.Views).Error; err != nil {
+			return nil, err
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.AdvertiserRepository = (*PGRepo)(nil)
+var _ entities.CampaignRepository = (*PGRepo)(nil)
+
+func (r *PGRepo) AddAdvertiser(ctx context.Context, advertiserName string) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(advertiserName); err != nil {
+		return err
+	}
+	advertiser := &Advertiser{
+		Advertiser: entities.Advertiser{Name: advertiserName},
+	}
+	if err := r.db.Create(advertiser).Error; err != nil {
+		return uniqueViolation(err, entities.ErrAdvertiserExist(advertiserName))
+	}
+	return nil
+}
+
+func (r *PGRepo) GetAdvertisers(ctx context.Context) (advertisers []entities.Advertiser, err error) {
+	r = r.withContext(ctx)
+	var as []*Advertiser
+	if err := r.db.Find(&as).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range as {
+		advertisers = append(advertisers, a.Advertiser)
+	}
+	return
+}
+
+func (r *PGRepo) SetAdvertiserPaused(ctx context.Context, advertiserName string, paused bool) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(advertiserName); err != nil {
+		return err
+	}
+	advertiser, err := r.getRepoAdvertiser(advertiserName)
+	if err != nil {
+		return err
+	}
+	// pause or resume all advertiser campaigns.
+	if err := r.db.Model(&Campaign{}).Where("advertiser_id = ?", advertiser.ID).UpdateColumn("paused", paused).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PGRepo) GetAdvertiserActions(ctx context.Context, advertiserName string) (actions map[entities.Group]entities.Action, err error) {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(advertiserName); err != nil {
+		return nil, err
+	}
+	advertiser, err := r.getRepoAdvertiser(advertiserName)
+	if err != nil {
+		return nil, err
+	}
+	query := r.bannerEventsQuery().
+		Joins("JOIN campaigns ON campaigns.id = banners.campaign_id").
+		Where("campaigns.advertiser_id = ?", advertiser.ID)
+	return r.sumActions(query)
+}
+
+func (r *PGRepo) AddCampaign(ctx context.Context, advertiserName, campaignName string) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(advertiserName, campaignName); err != nil {
+		return err
+	}
+	advertiser, err := r.getRepoAdvertiser(advertiserName)
+	if err != nil {
+		return err
+	}
+	campaign := &Campaign{
+		AdvertiserID: advertiser.ID,
+		Campaign:     entities.Campaign{Name: campaignName},
+	}
+	if err := r.db.Create(campaign).Error; err != nil {
+		return uniqueViolation(err, entities.ErrCampaignExist(campaignName))
+	}
+	return nil
+}
+
+func (r *PGRepo) GetCampaigns(ctx context.Context, advertiserName string) (campaigns []entities.Campaign, err error) {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(advertiserName); err != nil {
+		return nil, err
+	}
+	advertiser, err := r.getRepoAdvertiser(advertiserName)
+	if err != nil {
+		return nil, err
+	}
+	var cs []*Campaign
+	if err := r.db.Model(advertiser).Related(&cs).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range cs {
+		campaigns = append(campaigns, c.Campaign)
+	}
+	return
+}
+
+func (r *PGRepo) AddBannerToCampaign(ctx context.Context, campaignName string, bannerInnerID uint) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(campaignName, bannerInnerID); err != nil {
+		return err
+	}
+	campaign, err := r.getRepoCampaign(campaignName)
+	if err != nil {
+		return err
+	}
+	var banners []*Banner
+	if err := r.db.Where("inner_id = ?", bannerInnerID).Find(&banners).Error; err != nil {
+		return err
+	}
+	switch {
+	case len(banners) == 0:
+		return entities.ErrBannerIDNotFound(bannerInnerID)
+	case len(banners) > 1:
+		return entities.ErrBannerIDAmbiguous(bannerInnerID)
+	}
+	if err := r.db.Model(banners[0]).UpdateColumn("campaign_id", campaign.ID).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PGRepo) SetCampaignPaused(ctx context.Context, campaignName string, paused bool) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(campaignName); err != nil {
+		return err
+	}
+	campaign, err := r.getRepoCampaign(campaignName)
+	if err != nil {
+		return err
+	}
+	if err := r.db.Model(campaign).UpdateColumn("paused", paused).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PGRepo) GetCampaignActions(ctx context.Context, campaignName string) (actions map[entities.Group]entities.Action, err error) {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(campaignName); err != nil {
+		return nil, err
+	}
+	campaign, err := r.getRepoCampaign(campaignName)
+	if err != nil {
+		return nil, err
+	}
+	query := r.bannerEventsQuery().Where("banners.campaign_id = ?", campaign.ID)
+	return r.sumActions(query)
+}
+
+func (r *PGRepo) GetPausedBanners(ctx context.Context) (banners []entities.Banner, err error) {
+	r = r.withContext(ctx)
+	var bs []*Banner
+	if err := r.db.Select("banners.*").
+		Joins("JOIN campaigns ON campaigns.id = banners.campaign_id AND campaigns.paused = ?", true).
+		Order("banners.id").Find(&bs).Error; err != nil {
+		return nil, err
+	}
+	for _, banner := range bs {
+		banners = append(banners, banner.Banner)
+	}
+	return
+}
+
+func (r *PGRepo) getRepoAdvertiser(advertiserName string) (*Advertiser, error) {
+	advertiser := &Advertiser{
+		Advertiser: entities.Advertiser{Name: advertiserName},
+	}
+	if err := r.db.Where(advertiser).First(advertiser).Error; err != nil {
+		return nil, notFound(err, entities.ErrAdvertiserNotFound(advertiserName))
+	}
+	return advertiser, nil
+}
+
+func (r *PGRepo) getRepoCampaign(campaignName string) (*Campaign, error) {
+	campaign := &Campaign{
+		Campaign: entities.Campaign{Name: campaignName},
+	}
+	if err := r.db.Where(campaign).First(campaign).Error; err != nil {
+		return nil, notFound(err, entities.ErrCampaignNotFound(campaignName))
+	}
+	return campaign, nil
+}
+
+// bannerEventsQuery joins banner events with their banners.
+func (r *PGRepo) bannerEventsQuery() *gorm.DB {
+	return r.db.Table("banner_events").
+		Joins("JOIN banner_slots ON banner_slots.id = banner_events.banner_slot_id").
+		Joins("JOIN banners ON banners.id = banner_slots.banner_id")
+}
+
+type groupAction struct {
+	GroupID uint
+	entities.Action
+}
+
+// sumActions sums counters of banner events query for each user group.
+func (r *PGRepo) sumActions(query *gorm.DB) (actions map[entities.Group]entities.Action, err error) {
+	var sums []groupAction
+	if err := query.
+		Select("banner_events.group_id, SUM(banner_events.clicks) AS clicks, SUM(banner_events.shows) AS shows, SUM(banner_events.views) AS views").
+		Group("banner_events.group_id").
+		Scan(&sums).Error; err != nil {
+		return nil, err
+	}
+	groups, err := r.getRepoGroups()
+	if err != nil {
+		return nil, err
+	}
+	actions = make(map[entities.Group]entities.Action)
+	groupByID := make(map[uint]entities.Group)
+	for _, group := range groups {
+		groupByID[group.ID] = group.Group
+		actions[group.Group] = entities.Action{}
+	}
+	for _, sum := range sums {
+		if group, ok := groupByID[sum.GroupID]; ok {
+			actions[group] = sum.Action
+		}
+	}
+	return
+}
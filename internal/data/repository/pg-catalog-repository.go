@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.BannerCatalogRepository = (*PGRepo)(nil)
+
+func (r *PGRepo) AddCatalogBanner(ctx context.Context, bannerInnerID uint, bannerDescription string, creative entities.Creative) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(bannerInnerID, bannerDescription); err != nil {
+		return err
+	}
+	count := 0
+	if err := r.db.Model(&Banner{}).Where("inner_id = ? AND catalog = ?", bannerInnerID, true).Count(&count).Error; err != nil {
+		return err
+	}
+	if count != 0 {
+		return entities.ErrCatalogBannerExist(bannerInnerID)
+	}
+	banner := &Banner{
+		Banner: entities.Banner{
+			InnerID:     bannerInnerID,
+			Description: bannerDescription,
+		},
+	}
+	// banner can be already added to slots.
+	if err := r.db.Where(banner).Attrs(Banner{Banner: entities.Banner{Creative: creative}}).FirstOrInit(banner).Error; err != nil {
+		return err
+	}
+	banner.Catalog = true
+	if err := r.db.Save(banner).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PGRepo) GetCatalogBanners(ctx context.Context) (banners []entities.Banner, err error) {
+	r = r.withContext(ctx)
+	var bs []*Banner
+	if err := r.db.Where("catalog = ?", true).Order("inner_id").Find(&bs).Error; err != nil {
+		return nil, err
+	}
+	for _, banner := range bs {
+		banners = append(banners, banner.Banner)
+	}
+	return
+}
+
+// DeleteCatalogBanner deletes the detached catalog banner, banners attached to slots are kept with their stats.
+func (r *PGRepo) DeleteCatalogBanner(ctx context.Context, bannerInnerID uint) error {
+	if err := validateZeroParam(bannerInnerID); err != nil {
+		return err
+	}
+	return r.transaction(ctx, func(tx *PGRepo) error {
+		banner, err := tx.getRepoCatalogBanner(bannerInnerID)
+		if err != nil {
+			return err
+		}
+		count := 0
+		if err := tx.db.Model(&BannerSlot{}).Where("banner_id = ?", banner.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != 0 {
+			return entities.ErrCatalogBannerAttached(bannerInnerID)
+		}
+		return tx.db.Where(banner).Unscoped().Delete(banner).Error
+	})
+}
+
+func (r *PGRepo) AttachBanner(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID); err != nil {
+		return err
+	}
+	slot, err := r.getRepoSlot(pageURL, slotInnerID)
+	if err != nil {
+		return err
+	}
+	banner, err := r.getRepoCatalogBanner(bannerInnerID)
+	if err != nil {
+		return err
+	}
+	if err := r.db.Create(&BannerSlot{BannerID: banner.ID, SlotID: slot.ID}).Error; err != nil {
+		return uniqueViolation(err, entities.ErrBannerExist(slotInnerID, bannerInnerID, pageURL, banner.Description))
+	}
+	return nil
+}
+
+func (r *PGRepo) GetBannerActions(ctx context.Context, bannerInnerID uint) (actions map[entities.Group]entities.Action, err error) {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(bannerInnerID); err != nil {
+		return nil, err
+	}
+	banner, err := r.getRepoCatalogBanner(bannerInnerID)
+	if err != nil {
+		return nil, err
+	}
+	// slot banners can have the same inner id.
+	query := r.bannerEventsQuery().Where("banners.id = ?", banner.ID)
+	return r.sumActions(query)
+}
+
+func (r *PGRepo) getRepoCatalogBanner(bannerInnerID uint) (*Banner, error) {
+	banner := &Banner{}
+	if err := r.db.Where("inner_id = ? AND catalog = ?", bannerInnerID, true).First(banner).Error; err != nil {
+		return nil, notFound(err, entities.ErrCatalogBannerNotFound(bannerInnerID))
+	}
+	return banner, nil
+}
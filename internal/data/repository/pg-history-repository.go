@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.HistoryRepository = (*PGRepo)(nil)
+
+type bucketRow struct {
+	Hour             time.Time
+	SlotID           uint
+	BannerID         uint
+	GroupDescription string
+	entities.Action
+}
+
+func (r *PGRepo) GetBuckets(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint, from, to time.Time) (buckets []entities.StatBucket, err error) {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(pageURL); err != nil {
+		return nil, err
+	}
+	query := r.db.Table("banner_event_buckets").
+		Select("banner_event_buckets.hour, slots.inner_id AS slot_id, banners.inner_id AS banner_id, groups.description AS group_description, "+
+			"banner_event_buckets.clicks, banner_event_buckets.shows, banner_event_buckets.views").
+		Joins("JOIN banner_slots ON banner_slots.id = banner_event_buckets.banner_slot_id").
+		Joins("JOIN slots ON slots.id = banner_slots.slot_id").
+		Joins("JOIN pages ON pages.id = slots.page_id").
+		Joins("JOIN banners ON banners.id = banner_slots.banner_id").
+		Joins("JOIN groups ON groups.id = banner_event_buckets.group_id").
+		Where("pages.url = ? AND banner_event_buckets.hour >= ? AND banner_event_buckets.hour < ?", pageURL, from.UTC(), to.UTC())
+	if slotInnerID != 0 {
+		query = query.Where("slots.inner_id = ?", slotInnerID)
+	}
+	if bannerInnerID != 0 {
+		query = query.Where("banners.inner_id = ?", bannerInnerID)
+	}
+	var rows []bucketRow
+	if err := query.Order("banner_event_buckets.hour").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		buckets = append(buckets, entities.StatBucket{
+			Start:            row.Hour,
+			SlotID:           row.SlotID,
+			BannerID:         row.BannerID,
+			GroupDescription: row.GroupDescription,
+			Action:           row.Action,
+		})
+	}
+	return
+}
+
+func (r *PGRepo) DeleteBucketsBefore(ctx context.Context, t time.Time) error {
+	r = r.withContext(ctx)
+	if err := r.db.Where("hour < ?", t.UTC()).Unscoped().Delete(&BannerEventBucket{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+func (r *PGRepo) AddPage(ctx context.Context, pageURL string, settings entities.PageSettings) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(pageURL); err != nil {
+		return err
+	}
+	page := &Page{
+		Page: entities.Page{
+			URL:       pageURL,
+			Algorithm: settings.Algorithm,
+		},
+		AllowedCategories: strings.Join(settings.AllowedCategories, listSeparator),
+	}
+	if err := r.db.Create(page).Error; err != nil {
+		return uniqueViolation(err, entities.ErrPageExist(pageURL))
+	}
+	return nil
+}
+
+func (r *PGRepo) RenamePage(ctx context.Context, pageURL, newPageURL string) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(pageURL, newPageURL); err != nil {
+		return err
+	}
+	page, err := r.getRepoPage(pageURL)
+	if err != nil {
+		return err
+	}
+	if err := r.db.Model(page).UpdateColumn("url", newPageURL).Error; err != nil {
+		return uniqueViolation(err, entities.ErrPageExist(newPageURL))
+	}
+	return nil
+}
+
+func (r *PGRepo) SetPageSettings(ctx context.Context, pageURL string, settings entities.PageSettings) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(pageURL); err != nil {
+		return err
+	}
+	page, err := r.getRepoPage(pageURL)
+	if err != nil {
+		return err
+	}
+	page.Algorithm = settings.Algorithm
+	page.AllowedCategories = strings.Join(settings.AllowedCategories, listSeparator)
+	if err := r.db.Save(page).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PGRepo) GetPageSettings(ctx context.Context) (settings map[string]entities.PageSettings, err error) {
+	r = r.withContext(ctx)
+	ps, err := r.getRepoPages()
+	if err != nil {
+		return nil, err
+	}
+	settings = make(map[string]entities.PageSettings, len(ps))
+	for _, page := range ps {
+		settings[page.URL] = entities.PageSettings{
+			Algorithm:         page.Algorithm,
+			AllowedCategories: splitList(page.AllowedCategories),
+		}
+	}
+	return
+}
+
+func (r *PGRepo) DeletePage(ctx context.Context, pageURL string) error {
+	if err := validateZeroParam(pageURL); err != nil {
+		return err
+	}
+	return r.transaction(ctx, func(tx *PGRepo) error {
+		page, err := tx.getRepoPage(pageURL)
+		if err != nil {
+			return err
+		}
+		// delete slots with banners and events.
+		if err := tx.DeleteAllSlots(ctx, pageURL); err != nil {
+			return err
+		}
+		// delete page.
+		return tx.db.Where(page).Unscoped().Delete(page).Error
+	})
+}
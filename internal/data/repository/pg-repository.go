@@ -3,18 +3,22 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"database/sql"
 
 	"github.com/jinzhu/gorm"
 	// used by gorm
 	_ "github.com/jinzhu/gorm/dialects/postgres"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
 	"github.com/pkg/errors"
 
 	"github.com/shipa988/banner_rotator/internal/data/logger"
 	"github.com/shipa988/banner_rotator/internal/domain/entities"
 )
 
-const ErrZeroValue = "params has zerovalues"
+// pqUniqueViolation is the postgres error code of unique constraint violations.
+const pqUniqueViolation = "23505"
 
 var _ entities.BannerRepository = (*PGRepo)(nil)
 var _ entities.SlotRepository = (*PGRepo)(nil)
@@ -22,9 +26,12 @@ var _ entities.PageRepository = (*PGRepo)(nil)
 var _ entities.ActionRepository = (*PGRepo)(nil)
 var _ entities.GroupRepository = (*PGRepo)(nil)
 
+// PGRepo is the gorm repository, queries are dialect neutral to run on postgres and sqlite3.
 type PGRepo struct {
-	db     *gorm.DB
-	logger logger.Logger
+	db      *gorm.DB
+	conn    *sql.DB
+	logger  logger.Logger
+	isDebug bool
 }
 
 func NewPGRepo(db *gorm.DB, logger logger.Logger, isDebug bool) *PGRepo {
@@ -33,77 +40,90 @@ func NewPGRepo(db *gorm.DB, logger logger.Logger, isDebug bool) *PGRepo {
 		db.LogMode(true)
 		db.SetLogger(logger)
 	}
-	return &PGRepo{db: db, logger: logger}
+	return &PGRepo{db: db, conn: db.DB(), logger: logger, isDebug: isDebug}
+}
+
+// ctxDB runs gorm queries with the context, gorm v1 doesn't pass one to database/sql.
+type ctxDB struct {
+	*sql.DB
+	ctx context.Context
+}
+
+func (db *ctxDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(db.ctx, query, args...)
+}
+
+func (db *ctxDB) Prepare(query string) (*sql.Stmt, error) {
+	return db.PrepareContext(db.ctx, query)
+}
+
+func (db *ctxDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.QueryContext(db.ctx, query, args...)
+}
+
+func (db *ctxDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.QueryRowContext(db.ctx, query, args...)
+}
+
+func (db *ctxDB) Begin() (*sql.Tx, error) {
+	return db.BeginTx(db.ctx, nil)
+}
+
+// withContext returns the repository whose queries are aborted when ctx is done.
+func (r *PGRepo) withContext(ctx context.Context) *PGRepo {
+	if r.conn == nil {
+		// the repository already runs with a context or inside a transaction.
+		return r
+	}
+	db, err := gorm.Open(r.db.Dialect().GetName(), &ctxDB{DB: r.conn, ctx: ctx})
+	if err != nil {
+		return r
+	}
+	if r.isDebug {
+		db.LogMode(true)
+		db.SetLogger(r.logger)
+	}
+	return &PGRepo{db: db, logger: r.logger, isDebug: r.isDebug}
+}
+
+// transaction runs fn with the repository whose queries run in one transaction, it is rolled back if fn fails.
+func (r *PGRepo) transaction(ctx context.Context, fn func(tx *PGRepo) error) error {
+	tx := r.db.BeginTx(ctx, nil)
+	if err := tx.Error; err != nil {
+		return err
+	}
+	if err := fn(&PGRepo{db: tx, logger: r.logger, isDebug: r.isDebug}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
 }
 
 func (r *PGRepo) CreateDB() {
 	r.logger.Log(context.Background(), "db creating...")
 	//Миграция базы данных
-	if err := r.db.AutoMigrate(&Banner{}, &Slot{}, &Page{}, &Group{}, &BannerEvent{}, &BannerSlot{}).Error; err != nil {
+	if _, err := r.MigrateUp(context.Background()); err != nil {
 		r.logger.Log(context.Background(), errors.Wrapf(err, "can't create db"))
-	}
-	groups := []*entities.Group{
-		{
-			Description: "young man",
-			Sex:         "man",
-			MinAge:      0,
-			MaxAge:      40,
-		},
-		{
-			Description: "young women",
-			Sex:         "women",
-			MinAge:      0,
-			MaxAge:      40,
-		},
-		{
-			Description: "middle-age man",
-			Sex:         "man",
-			MinAge:      41,
-			MaxAge:      60,
-		},
-		{
-			Description: "middle-age women",
-			Sex:         "women",
-			MinAge:      41,
-			MaxAge:      60,
-		},
-		{
-			Description: "old man",
-			Sex:         "man",
-			MinAge:      61,
-			MaxAge:      150,
-		},
-		{
-			Description: "old women",
-			Sex:         "women",
-			MinAge:      61,
-			MaxAge:      150,
-		},
-		{
-			Description: "unknown age-sex group",
-			Sex:         "unknown",
-			MinAge:      0,
-			MaxAge:      0,
-		},
-	}
-
-	for _, group := range groups {
-		if err := r.db.Save(group).Error; err != nil {
-			r.logger.Log(context.Background(), errors.Wrapf(err, "can't add group to db"))
-		}
+		return
 	}
 	r.logger.Log(context.Background(), "db creation complete")
 }
 
 func (r *PGRepo) DeleteDB() {
 	r.logger.Log(context.Background(), "db deleting...")
-	if err := r.db.DropTableIfExists(&Banner{}, &Slot{}, &Page{}, &Group{}, &BannerEvent{}, &BannerSlot{}).Error; err != nil {
+	migrations, err := loadMigrations(r.db.Dialect().GetName())
+	if err != nil {
+		r.logger.Log(context.Background(), errors.Wrapf(err, "can't delete db"))
+		return
+	}
+	if _, err := r.MigrateDown(context.Background(), len(migrations)); err != nil {
 		r.logger.Log(context.Background(), errors.Wrapf(err, "can't delete db"))
 	}
 	r.logger.Log(context.Background(), "db deleting complete...")
 }
 
-func (r *PGRepo) GetPages() (pages []entities.Page, err error) {
+func (r *PGRepo) GetPages(ctx context.Context) (pages []entities.Page, err error) {
+	r = r.withContext(ctx)
 	ps, err := r.getRepoPages()
 	if err != nil {
 		return nil, err
@@ -116,7 +136,8 @@ func (r *PGRepo) GetPages() (pages []entities.Page, err error) {
 	return
 }
 
-func (r *PGRepo) GetSlotsByPageURL(pageURL string) (slots []entities.Slot, err error) {
+func (r *PGRepo) GetSlotsByPageURL(ctx context.Context, pageURL string) (slots []entities.Slot, err error) {
+	r = r.withContext(ctx)
 	if err := validateZeroParam(pageURL); err != nil {
 		return nil, err
 	}
@@ -132,7 +153,8 @@ func (r *PGRepo) GetSlotsByPageURL(pageURL string) (slots []entities.Slot, err e
 	return
 }
 
-func (r *PGRepo) GetBannersBySlotID(pageURL string, slotInnerID uint) (banners []entities.Banner, err error) {
+func (r *PGRepo) GetBannersBySlotID(ctx context.Context, pageURL string, slotInnerID uint) (banners []entities.Banner, err error) {
+	r = r.withContext(ctx)
 	if err := validateZeroParam(pageURL, slotInnerID); err != nil {
 		return nil, err
 	}
@@ -148,7 +170,35 @@ func (r *PGRepo) GetBannersBySlotID(pageURL string, slotInnerID uint) (banners [
 	return
 }
 
-func (r *PGRepo) GetGroup(userAge uint, userSex string) (group *entities.Group, err error) {
+func (r *PGRepo) GetSlot(ctx context.Context, pageURL string, slotInnerID uint) (slot *entities.Slot, err error) {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(pageURL, slotInnerID); err != nil {
+		return nil, err
+	}
+	repoSlot, err := r.getRepoSlot(pageURL, slotInnerID)
+	if err != nil {
+		return nil, err
+	}
+	return &repoSlot.Slot, nil
+}
+
+func (r *PGRepo) GetBanner(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) (banner *entities.Banner, err error) {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID); err != nil {
+		return nil, err
+	}
+	repoBanners, err := r.getRepoBanners(pageURL, slotInnerID, bannerInnerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(repoBanners) == 0 {
+		return nil, entities.ErrBannerNotFound(slotInnerID, bannerInnerID, pageURL)
+	}
+	return &repoBanners[0].Banner, nil
+}
+
+func (r *PGRepo) GetGroup(ctx context.Context, userAge uint, userSex string) (group *entities.Group, err error) {
+	r = r.withContext(ctx)
 	if err := validateZeroParam(userAge, userSex); err != nil {
 		return nil, err
 	}
@@ -159,14 +209,15 @@ func (r *PGRepo) GetGroup(userAge uint, userSex string) (group *entities.Group,
 	return &repoGroup.Group, nil
 }
 
-func (r *PGRepo) GetGroups() (groups []entities.Group, defaultGroupDescription string, err error) {
+func (r *PGRepo) GetGroups(ctx context.Context) (groups []entities.Group, defaultGroupDescription string, err error) {
+	r = r.withContext(ctx)
 	gs, err := r.getRepoGroups()
 	if err != nil {
 		return nil, "", err
 	}
 
 	for _, g := range gs {
-		if g.MinAge == 0 && g.MaxAge == 0 {
+		if g.IsDefault {
 			defaultGroupDescription = g.Description
 		}
 		groups = append(groups, g.Group)
@@ -175,7 +226,84 @@ func (r *PGRepo) GetGroups() (groups []entities.Group, defaultGroupDescription s
 	return
 }
 
-func (r *PGRepo) GetActions(pageURL string, slotInnerID, bannerInnerID uint) (actions map[entities.Group]entities.Action, err error) {
+func (r *PGRepo) GetGroupRules(ctx context.Context) (rules map[string][]entities.Rule, err error) {
+	r = r.withContext(ctx)
+	var groups []*Group
+	if err := r.db.Preload("Rules").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	rules = make(map[string][]entities.Rule)
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			rules[group.Description] = append(rules[group.Description], rule.Rule)
+		}
+	}
+	return
+}
+
+func (r *PGRepo) AddGroup(ctx context.Context, group entities.Group, rules []entities.Rule) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(group.Description); err != nil {
+		return err
+	}
+	if err := r.db.Create(&Group{Group: group, Rules: toRepoRules(rules)}).Error; err != nil {
+		return uniqueViolation(err, entities.ErrGroupExist(group.Description))
+	}
+	return nil
+}
+
+func (r *PGRepo) UpdateGroup(ctx context.Context, groupDescription string, group entities.Group, rules []entities.Rule) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(groupDescription, group.Description); err != nil {
+		return err
+	}
+	repoGroup, err := r.getRepoGroupByDescription(groupDescription)
+	if err != nil {
+		return err
+	}
+	// replace group rules.
+	if err := r.db.Where("group_id=?", repoGroup.ID).Unscoped().Delete(&GroupRule{}).Error; err != nil {
+		return err
+	}
+	repoGroup.Group = group
+	repoGroup.Rules = toRepoRules(rules)
+	if err := r.db.Save(repoGroup).Error; err != nil {
+		return uniqueViolation(err, entities.ErrGroupExist(group.Description))
+	}
+	return nil
+}
+
+func (r *PGRepo) DeleteGroup(ctx context.Context, groupDescription string) error {
+	if err := validateZeroParam(groupDescription); err != nil {
+		return err
+	}
+	return r.transaction(ctx, func(tx *PGRepo) error {
+		group, err := tx.getRepoGroupByDescription(groupDescription)
+		if err != nil {
+			return err
+		}
+		// events and buckets are the group history.
+		events, buckets := 0, 0
+		if err := tx.db.Model(&BannerEvent{}).Where("group_id=?", group.ID).Count(&events).Error; err != nil {
+			return err
+		}
+		if err := tx.db.Model(&BannerEventBucket{}).Where("group_id=?", group.ID).Count(&buckets).Error; err != nil {
+			return err
+		}
+		if events+buckets != 0 {
+			return entities.ErrGroupHasEvents(groupDescription)
+		}
+		// delete group rules.
+		if err := tx.db.Where("group_id=?", group.ID).Unscoped().Delete(&GroupRule{}).Error; err != nil {
+			return err
+		}
+		// delete group.
+		return tx.db.Where(group).Unscoped().Delete(group).Error
+	})
+}
+
+func (r *PGRepo) GetActions(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) (actions map[entities.Group]entities.Action, err error) {
+	r = r.withContext(ctx)
 	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID); err != nil {
 		return nil, err
 	}
@@ -189,7 +317,7 @@ func (r *PGRepo) GetActions(pageURL string, slotInnerID, bannerInnerID uint) (ac
 	// get events.
 	r.db.Model(bannerSlot).Related(&events, "Events")
 	// get all groups.
-	groups, _, err := r.GetGroups()
+	groups, _, err := r.GetGroups(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -197,6 +325,7 @@ func (r *PGRepo) GetActions(pageURL string, slotInnerID, bannerInnerID uint) (ac
 		actions[group] = entities.Action{
 			Clicks: 0,
 			Shows:  0,
+			Views:  0,
 		}
 	}
 	// to fill in groups-actions
@@ -209,7 +338,8 @@ func (r *PGRepo) GetActions(pageURL string, slotInnerID, bannerInnerID uint) (ac
 	return
 }
 
-func (r *PGRepo) AddSlot(pageURL string, slotInnerID uint, slotDescription string) (err error) {
+func (r *PGRepo) AddSlot(ctx context.Context, pageURL string, slotInnerID uint, slotDescription string, viewableTry bool, width, height uint) (err error) {
+	r = r.withContext(ctx)
 	if err := validateZeroParam(pageURL, slotInnerID, slotDescription); err != nil {
 		return err
 	}
@@ -227,17 +357,21 @@ func (r *PGRepo) AddSlot(pageURL string, slotInnerID uint, slotDescription strin
 		Slot: entities.Slot{
 			InnerID:     slotInnerID,
 			Description: slotDescription,
+			ViewableTry: viewableTry,
+			Width:       width,
+			Height:      height,
 		},
 	}
 	// create slot if not exist.
 	if err := r.db.Create(slot).Error; err != nil {
-		return err
+		return uniqueViolation(err, entities.ErrSlotExist(slotInnerID, pageURL))
 	}
 
 	return nil
 }
 
-func (r *PGRepo) AddBannerToSlot(pageURL string, slotInnerID uint, bannerInnerID uint, bannerDescription string) (err error) {
+func (r *PGRepo) AddBannerToSlot(ctx context.Context, pageURL string, slotInnerID uint, bannerInnerID uint, bannerDescription string, creative entities.Creative) (err error) {
+	r = r.withContext(ctx)
 	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID, bannerDescription); err != nil {
 		return err
 	}
@@ -252,10 +386,14 @@ func (r *PGRepo) AddBannerToSlot(pageURL string, slotInnerID uint, bannerInnerID
 			Description: bannerDescription,
 		},
 	}
-	// get banner if exist, if not-create
-	if err := r.db.Where(banner).FirstOrCreate(banner).Error; err != nil {
+	// get banner if exist, if not-create with creative.
+	if err := r.db.Where(banner).Attrs(Banner{Banner: entities.Banner{Creative: creative}}).FirstOrCreate(banner).Error; err != nil {
 		return err
 	}
+	// the banner is shared by slots, so its creative isn't replaced silently.
+	if banner.Creative != creative {
+		return entities.ErrBannerCreativeExist(bannerInnerID, bannerDescription)
+	}
 	// update banner with ID.
 	banner.BannerSlots = []*BannerSlot{{
 		BannerID: banner.ID,
@@ -263,81 +401,56 @@ func (r *PGRepo) AddBannerToSlot(pageURL string, slotInnerID uint, bannerInnerID
 	}}
 	// update in DB.
 	if err := r.db.Save(banner).Error; err != nil {
-		return err
+		return uniqueViolation(err, entities.ErrBannerExist(slotInnerID, bannerInnerID, pageURL, bannerDescription))
 	}
 
 	return nil
 }
 
-func (r *PGRepo) AddClickAction(pageURL string, slotInnerID, bannerInnerID, userAge uint, userSex string) error {
-	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID, userAge, userSex); err != nil {
+func (r *PGRepo) UpdateSlot(ctx context.Context, pageURL string, slotInnerID uint, slotDescription string, viewableTry bool, width, height uint) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(pageURL, slotInnerID, slotDescription); err != nil {
 		return err
 	}
-	bannerSlot, err := r.getRepoBannerSlot(pageURL, slotInnerID, bannerInnerID)
+	slot, err := r.getRepoSlot(pageURL, slotInnerID)
 	if err != nil {
 		return err
 	}
-	// init group
-	group, err := r.getRepoGroup(userAge, userSex)
-	if err != nil {
+	slot.Description = slotDescription
+	slot.ViewableTry = viewableTry
+	slot.Width = width
+	slot.Height = height
+	// banner slots are not touched.
+	if err := r.db.Save(slot).Error; err != nil {
 		return err
 	}
-	if group.ID != 0 {
-		var event = &BannerEvent{
-			BannerSlotID: bannerSlot.ID,
-			GroupID:      group.ID,
-		}
-
-		count := 0
-		r.db.Model(&bannerSlot).Related("Events").Where(&event).FirstOrCreate(&event).Count(&count)
-		if count == 0 {
-			bannerSlot.Events = []*BannerEvent{event}
-			// update in DB.
-			if err := r.db.Save(bannerSlot).Error; err != nil {
-				return err
-			}
-		}
-		r.db.Model(event).Where(event).UpdateColumn("clicks", gorm.Expr("clicks + $1", 1))
-	}
-
 	return nil
 }
 
-func (r *PGRepo) AddShowAction(pageURL string, slotInnerID, bannerInnerID, userAge uint, userSex string) error {
-	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID, userAge, userSex); err != nil {
+func (r *PGRepo) UpdateBanner(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint, bannerDescription string, creative entities.Creative) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(pageURL, slotInnerID, bannerInnerID, bannerDescription); err != nil {
 		return err
 	}
-	bannerSlot, err := r.getRepoBannerSlot(pageURL, slotInnerID, bannerInnerID)
+	banners, err := r.getRepoBanners(pageURL, slotInnerID, bannerInnerID)
 	if err != nil {
 		return err
 	}
-	// init group
-	group, err := r.getRepoGroup(userAge, userSex)
-	if err != nil {
-		return err
+	if len(banners) == 0 {
+		return entities.ErrBannerNotFound(slotInnerID, bannerInnerID, pageURL)
 	}
-	if group.ID != 0 {
-		var event = &BannerEvent{
-			BannerSlotID: bannerSlot.ID,
-			GroupID:      group.ID,
-		}
-
-		count := 0
-		r.db.Model(&bannerSlot).Related("Events").Where(&event).FirstOrCreate(&event).Count(&count)
-		if count == 0 {
-			bannerSlot.Events = []*BannerEvent{event}
-			// update in DB.
-			if err := r.db.Save(bannerSlot).Error; err != nil {
-				return err
-			}
-		}
-		r.db.Model(event).Where(event).UpdateColumn("shows", gorm.Expr("shows + $1", 1))
+	banner := banners[0]
+	banner.Description = bannerDescription
+	banner.Creative = creative
+	// banner slots and events are not touched.
+	if err := r.db.Save(banner).Error; err != nil {
+		return uniqueViolation(err, entities.ErrBannerExist(slotInnerID, bannerInnerID, pageURL, bannerDescription))
 	}
-
 	return nil
 }
 
-func (r *PGRepo) DeleteSlot(pageURL string, slotInnerID uint) error {
+func (r *PGRepo) DeleteSlot(ctx context.Context, pageURL string, slotInnerID uint) error {
+	r = r.withContext(ctx)
 	if err := validateZeroParam(pageURL, slotInnerID); err != nil {
 		return err
 	}
@@ -346,7 +459,7 @@ func (r *PGRepo) DeleteSlot(pageURL string, slotInnerID uint) error {
 		return err
 	}
 	// delete banners from slot.
-	if err := r.DeleteAllBannersFormSlot(pageURL, slotInnerID); err != nil {
+	if err := r.DeleteAllBannersFormSlot(ctx, pageURL, slotInnerID); err != nil {
 		return err
 	}
 	// delete slot.
@@ -354,7 +467,8 @@ func (r *PGRepo) DeleteSlot(pageURL string, slotInnerID uint) error {
 	return nil
 }
 
-func (r *PGRepo) DeleteBannerFromSlot(pageURL string, slotInnerID, bannerInnerID uint) error {
+func (r *PGRepo) DeleteBannerFromSlot(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) error {
+	r = r.withContext(ctx)
 	if err := validateZeroParam(pageURL, slotInnerID, slotInnerID, bannerInnerID); err != nil {
 		return err
 	}
@@ -372,12 +486,16 @@ func (r *PGRepo) DeleteBannerFromSlot(pageURL string, slotInnerID, bannerInnerID
 		if err := r.db.Model(&BannerEvent{}).Where("banner_slot_id=?", bannerSlot.ID).Unscoped().Delete(&BannerEvent{}).Error; err != nil {
 			return err
 		}
+		// delete bannerSlot event buckets
+		if err := r.db.Where("banner_slot_id=?", bannerSlot.ID).Unscoped().Delete(&BannerEventBucket{}).Error; err != nil {
+			return err
+		}
 		// delete bannerSlot
 		if err := r.db.Where(bannerSlot).Unscoped().Delete(bannerSlot).Error; err != nil {
 			return err
 		}
 		//delete banner if it doesn't contain at least one relation to slot
-		if cnt := r.db.Model(banner).Association("BannerSlots").Count(); cnt == 0 {
+		if cnt := r.db.Model(banner).Association("BannerSlots").Count(); cnt == 0 && !banner.Catalog {
 			r.db.Where(banner).Unscoped().Delete(banner)
 		}
 	}
@@ -385,7 +503,8 @@ func (r *PGRepo) DeleteBannerFromSlot(pageURL string, slotInnerID, bannerInnerID
 	return nil
 }
 
-func (r *PGRepo) DeleteAllSlots(pageURL string) error {
+func (r *PGRepo) DeleteAllSlots(ctx context.Context, pageURL string) error {
+	r = r.withContext(ctx)
 	if err := validateZeroParam(pageURL); err != nil {
 		return err
 	}
@@ -395,7 +514,7 @@ func (r *PGRepo) DeleteAllSlots(pageURL string) error {
 	}
 
 	for _, slot := range slots {
-		if err := r.DeleteSlot(pageURL, slot.InnerID); err != nil {
+		if err := r.DeleteSlot(ctx, pageURL, slot.InnerID); err != nil {
 			return err
 		}
 	}
@@ -403,17 +522,18 @@ func (r *PGRepo) DeleteAllSlots(pageURL string) error {
 	return nil
 }
 
-func (r *PGRepo) DeleteAllBannersFormSlot(pageURL string, slotInnerID uint) error {
+func (r *PGRepo) DeleteAllBannersFormSlot(ctx context.Context, pageURL string, slotInnerID uint) error {
+	r = r.withContext(ctx)
 	if err := validateZeroParam(pageURL, slotInnerID); err != nil {
 		return err
 	}
-	banners, err := r.GetBannersBySlotID(pageURL, slotInnerID)
+	banners, err := r.GetBannersBySlotID(ctx, pageURL, slotInnerID)
 	if err != nil {
 		return err
 	}
 	//delete loop
 	for _, banner := range banners {
-		if err := r.DeleteBannerFromSlot(pageURL, slotInnerID, banner.InnerID); err != nil {
+		if err := r.DeleteBannerFromSlot(ctx, pageURL, slotInnerID, banner.InnerID); err != nil {
 			return err
 		}
 	}
@@ -428,7 +548,7 @@ func (r *PGRepo) getRepoPage(pageURL string) (*Page, error) {
 	}
 	// get page.
 	if err := r.db.Where(page).First(page).Error; err != nil {
-		return nil, err
+		return nil, notFound(err, entities.ErrPageNotFound(pageURL))
 	}
 	return page, nil
 }
@@ -456,7 +576,7 @@ func (r *PGRepo) getRepoSlot(pageURL string, slotInnerID uint) (*Slot, error) {
 	}
 	//get slot.
 	if err := r.db.Where(slot).First(slot).Error; err != nil {
-		return nil, err
+		return nil, notFound(err, entities.ErrSlotNotFound(slotInnerID, pageURL))
 	}
 	return slot, nil
 }
@@ -517,15 +637,25 @@ func (r *PGRepo) getRepoBannerSlot(pageURL string, slotInnerID uint, bannerInner
 		Joins("JOIN banner_slots on banner_slots.slot_id = slots.id AND slots.inner_id = ?", slotInnerID).
 		Joins("JOIN banners on banner_slots.banner_id = banners.id AND banners.inner_id = ?", bannerInnerID).
 		First(bannerSlot).Error; err != nil {
-		return nil, err
+		return nil, notFound(err, entities.ErrBannerNotFound(slotInnerID, bannerInnerID, pageURL))
 	}
 	return bannerSlot, nil
 }
 
 func (r *PGRepo) getRepoGroup(userAge uint, userSex string) (*Group, error) {
 	var group = &Group{}
-	if err := r.db.Model(&Group{}).Where("min_age<$1 AND max_age>=$1 AND sex=$2", userAge, userSex).First(group).Error; err != nil {
-		return nil, err
+	if err := r.db.Model(&Group{}).Where("min_age<? AND max_age>=? AND sex=?", userAge, userAge, userSex).First(group).Error; err != nil {
+		return nil, notFound(err, entities.ErrGroupForUserNotFound(userAge, userSex))
+	}
+	return group, nil
+}
+
+func (r *PGRepo) getRepoGroupByDescription(groupDescription string) (*Group, error) {
+	group := &Group{
+		Group: entities.Group{Description: groupDescription},
+	}
+	if err := r.db.Where(group).First(group).Error; err != nil {
+		return nil, notFound(err, entities.ErrGroupNotFound(groupDescription))
 	}
 	return group, nil
 }
@@ -537,18 +667,47 @@ func (r *PGRepo) getRepoGroups() (groups []Group, err error) {
 	return
 }
 
+func toRepoRules(rules []entities.Rule) []*GroupRule {
+	repoRules := make([]*GroupRule, 0, len(rules))
+	for _, rule := range rules {
+		repoRules = append(repoRules, &GroupRule{Rule: rule})
+	}
+	return repoRules
+}
+
 func validateZeroParam(params ...interface{}) error {
 	for _, param := range params {
 		switch v := param.(type) {
 		case string:
 			if v == "" {
-				return fmt.Errorf(ErrZeroValue)
+				return entities.ErrZeroValue()
 			}
 		case uint:
 			if v == 0 {
-				return fmt.Errorf(ErrZeroValue)
+				return entities.ErrZeroValue()
 			}
 		}
 	}
 	return nil
 }
+
+// notFound replaces gorm.ErrRecordNotFound with the domain error.
+func notFound(err, domainErr error) error {
+	if gorm.IsRecordNotFoundError(err) {
+		return domainErr
+	}
+	return err
+}
+
+// uniqueViolation replaces unique constraint errors of postgres and sqlite3 with the domain error.
+func uniqueViolation(err, domainErr error) error {
+	var pqErr *pq.Error
+	var sqliteErr sqlite3.Error
+	switch {
+	case errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation:
+	case errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique:
+	default:
+		return err
+	}
+	return domainErr
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"os"
@@ -52,6 +53,7 @@ func (s *Suite) SetupSuite() {
 }
 
 func (s *Suite) TestPGRepo_AddSlot() {
+	ctx := context.Background()
 	var (
 		url   = "site.com"
 		id    = 1
@@ -61,9 +63,9 @@ func (s *Suite) TestPGRepo_AddSlot() {
 	expectedRowsins := sqlmock.NewRows([]string{"id"}).AddRow(id)
 	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "pages"`)).WithArgs(url).WillReturnRows(expectedRows)
 	s.mock.ExpectBegin()
-	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "slots"`)).WithArgs(AnyTime{}, AnyTime{}, nil, id, id, descr).WillReturnRows(expectedRowsins)
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "slots"`)).WithArgs(AnyTime{}, AnyTime{}, nil, id, id, descr, 0, 0).WillReturnRows(expectedRowsins)
 	s.mock.ExpectCommit()
-	err := s.repository.AddSlot(url, uint(id), descr)
+	err := s.repository.AddSlot(ctx, url, uint(id), descr, false, 0, 0)
 	require.NoError(s.T(), err)
 }
 
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.TargetingRepository = (*PGRepo)(nil)
+var _ entities.TagsRepository = (*PGRepo)(nil)
+
+const listSeparator = ","
+
+func (r *PGRepo) SetBannerTargeting(ctx context.Context, bannerInnerID uint, targeting entities.Targeting) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(bannerInnerID); err != nil {
+		return err
+	}
+	// check banner exists.
+	if err := r.db.Where("inner_id = ?", bannerInnerID).First(&Banner{}).Error; err != nil {
+		return notFound(err, entities.ErrBannerIDNotFound(bannerInnerID))
+	}
+	repoTargeting := &BannerTargeting{BannerInnerID: bannerInnerID}
+	if err := r.db.Where(repoTargeting).FirstOrInit(repoTargeting).Error; err != nil {
+		return err
+	}
+	repoTargeting.MinAge = targeting.MinAge
+	repoTargeting.MaxAge = targeting.MaxAge
+	repoTargeting.Sex = targeting.Sex
+	repoTargeting.IncludeGroups = strings.Join(targeting.IncludeGroups, listSeparator)
+	repoTargeting.ExcludeGroups = strings.Join(targeting.ExcludeGroups, listSeparator)
+	if err := r.db.Save(repoTargeting).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PGRepo) DeleteBannerTargeting(ctx context.Context, bannerInnerID uint) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(bannerInnerID); err != nil {
+		return err
+	}
+	if err := r.db.Where("banner_inner_id = ?", bannerInnerID).Unscoped().Delete(&BannerTargeting{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PGRepo) GetTargetings(ctx context.Context) (targetings map[uint]entities.Targeting, err error) {
+	r = r.withContext(ctx)
+	var ts []*BannerTargeting
+	if err := r.db.Find(&ts).Error; err != nil {
+		return nil, err
+	}
+	targetings = make(map[uint]entities.Targeting, len(ts))
+	for _, t := range ts {
+		targetings[t.BannerInnerID] = entities.Targeting{
+			IncludeGroups: splitList(t.IncludeGroups),
+			ExcludeGroups: splitList(t.ExcludeGroups),
+			MinAge:        t.MinAge,
+			MaxAge:        t.MaxAge,
+			Sex:           t.Sex,
+		}
+	}
+	return
+}
+
+func (r *PGRepo) SetBannerTags(ctx context.Context, bannerInnerID uint, tags entities.Tags) error {
+	r = r.withContext(ctx)
+	if err := validateZeroParam(bannerInnerID); err != nil {
+		return err
+	}
+	// check banner exists.
+	if err := r.db.Where("inner_id = ?", bannerInnerID).First(&Banner{}).Error; err != nil {
+		return notFound(err, entities.ErrBannerIDNotFound(bannerInnerID))
+	}
+	repoTags := &BannerTags{BannerInnerID: bannerInnerID}
+	if err := r.db.Where(repoTags).FirstOrInit(repoTags).Error; err != nil {
+		return err
+	}
+	repoTags.Categories = strings.Join(tags.Categories, listSeparator)
+	repoTags.Competitors = strings.Join(tags.Competitors, listSeparator)
+	if err := r.db.Save(repoTags).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PGRepo) GetTags(ctx context.Context) (tags map[uint]entities.Tags, err error) {
+	r = r.withContext(ctx)
+	var ts []*BannerTags
+	if err := r.db.Find(&ts).Error; err != nil {
+		return nil, err
+	}
+	tags = make(map[uint]entities.Tags, len(ts))
+	for _, t := range ts {
+		tags[t.BannerInnerID] = entities.Tags{
+			Categories:  splitList(t.Categories),
+			Competitors: splitList(t.Competitors),
+		}
+	}
+	return
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, listSeparator)
+}
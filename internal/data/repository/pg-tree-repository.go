@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+var _ entities.PageTreeRepository = (*PGRepo)(nil)
+
+type treeBannerRow struct {
+	BannerSlotID uint
+	SlotID       uint
+	entities.Banner
+}
+
+type treeEventRow struct {
+	BannerSlotID uint
+	GroupID      uint
+	entities.Action
+}
+
+func (r *PGRepo) GetPageTrees(ctx context.Context, pageURL string) (pages []entities.PageTree, err error) {
+	r = r.withContext(ctx)
+	var ps []*Page
+	query := r.db.Order("id")
+	if pageURL != "" {
+		query = query.Where("url = ?", pageURL)
+	}
+	if err := query.Find(&ps).Error; err != nil {
+		return nil, err
+	}
+	if pageURL != "" && len(ps) == 0 {
+		return nil, entities.ErrPageNotFound(pageURL)
+	}
+	pageIDs := make([]uint, 0, len(ps))
+	for _, page := range ps {
+		pageIDs = append(pageIDs, page.ID)
+	}
+	// filter joined rows by pages when the only page is requested.
+	byPages := func(query *gorm.DB) *gorm.DB {
+		if pageURL == "" {
+			return query
+		}
+		return query.Where("slots.page_id IN (?)", pageIDs)
+	}
+
+	var slots []*Slot
+	slotQuery := r.db.Table("slots").Order("slots.id")
+	if err := byPages(slotQuery).Find(&slots).Error; err != nil {
+		return nil, err
+	}
+	var banners []treeBannerRow
+	bannerQuery := r.db.Table("banner_slots").
+		Select("banner_slots.id AS banner_slot_id, banner_slots.slot_id, banners.*").
+		Joins("JOIN slots ON slots.id = banner_slots.slot_id").
+		Joins("JOIN banners ON banners.id = banner_slots.banner_id").
+		Order("banner_slots.id")
+	if err := byPages(bannerQuery).Scan(&banners).Error; err != nil {
+		return nil, err
+	}
+	var events []treeEventRow
+	eventQuery := r.db.Table("banner_events").
+		Select("banner_events.banner_slot_id, banner_events.group_id, banner_events.clicks, banner_events.shows, banner_events.views").
+		Joins("JOIN banner_slots ON banner_slots.id = banner_events.banner_slot_id").
+		Joins("JOIN slots ON slots.id = banner_slots.slot_id")
+	if err := byPages(eventQuery).Scan(&events).Error; err != nil {
+		return nil, err
+	}
+	groups, err := r.getRepoGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	groupByID := make(map[uint]entities.Group, len(groups))
+	for _, group := range groups {
+		groupByID[group.ID] = group.Group
+	}
+	actions := make(map[uint]map[entities.Group]entities.Action)
+	for _, event := range events {
+		if group, ok := groupByID[event.GroupID]; ok {
+			if actions[event.BannerSlotID] == nil {
+				actions[event.BannerSlotID] = make(map[entities.Group]entities.Action)
+			}
+			actions[event.BannerSlotID][group] = event.Action
+		}
+	}
+	slotBanners := make(map[uint][]entities.BannerTree)
+	for _, banner := range banners {
+		bannerActions := make(map[entities.Group]entities.Action, len(groups))
+		for _, group := range groups {
+			bannerActions[group.Group] = actions[banner.BannerSlotID][group.Group]
+		}
+		slotBanners[banner.SlotID] = append(slotBanners[banner.SlotID], entities.BannerTree{
+			Banner:  banner.Banner,
+			Actions: bannerActions,
+		})
+	}
+	pageSlots := make(map[uint][]entities.SlotTree)
+	for _, slot := range slots {
+		pageSlots[slot.PageID] = append(pageSlots[slot.PageID], entities.SlotTree{
+			Slot:    slot.Slot,
+			Banners: slotBanners[slot.ID],
+		})
+	}
+	for _, page := range ps {
+		pages = append(pages, entities.PageTree{
+			Page:  page.Page,
+			Slots: pageSlots[page.ID],
+		})
+	}
+	return
+}
@@ -0,0 +1,564 @@
+package repository
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
+)
+
+// testRepository is implemented by all repositories of the package.
+type testRepository interface {
+	entities.PageRepository
+	entities.SlotRepository
+	entities.BannerRepository
+	entities.ActionRepository
+	entities.GroupRepository
+	entities.AdvertiserRepository
+	entities.CampaignRepository
+	entities.TargetingRepository
+	entities.TagsRepository
+	entities.BannerCatalogRepository
+	entities.HistoryRepository
+	entities.PageTreeRepository
+	entities.ActionBatchRepository
+	CreateDB()
+}
+
+// testRepositories returns repositories with the same data for each implementation.
+func testRepositories(t *testing.T) map[string]testRepository {
+	ctx := context.Background()
+	logger := zaplogger.NewLogger(ioutil.Discard, false)
+	dir, err := ioutil.TempDir("", "rotator")
+	require.NoError(t, err)
+	db, err := gorm.Open("sqlite3", filepath.Join(dir, "rotator.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+	repos := map[string]testRepository{
+		"memory": NewMemRepo(logger),
+		"sqlite": NewPGRepo(db, logger, false),
+	}
+	for _, repo := range repos {
+		repo.CreateDB()
+		for _, group := range testGroups() {
+			require.NoError(t, repo.AddGroup(ctx, *group, nil))
+		}
+		require.NoError(t, repo.AddSlot(ctx, "site.com", 1, "top", false, 0, 0))
+		require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 1, 1, "sale", entities.Creative{}))
+	}
+	return repos
+}
+
+// testAction adds the action of the current hour to the banner.
+func testAction(t *testing.T, repo testRepository, pageURL string, slotID, bannerID uint, groupDescription string, action entities.Action) {
+	skipped, err := repo.AddActions(context.Background(), []entities.ActionIncrement{{
+		PageURL:          pageURL,
+		SlotID:           slotID,
+		BannerID:         bannerID,
+		GroupDescription: groupDescription,
+		Hour:             time.Now().UTC().Truncate(time.Hour),
+		Action:           action,
+	}})
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+}
+
+// testGroups are age-sex groups of the default seed fixture.
+func testGroups() []*entities.Group {
+	return []*entities.Group{
+		{
+			Description: "young man",
+			Sex:         "man",
+			MinAge:      0,
+			MaxAge:      40,
+		},
+		{
+			Description: "young women",
+			Sex:         "women",
+			MinAge:      0,
+			MaxAge:      40,
+		},
+		{
+			Description: "middle-age man",
+			Sex:         "man",
+			MinAge:      41,
+			MaxAge:      60,
+		},
+		{
+			Description: "middle-age women",
+			Sex:         "women",
+			MinAge:      41,
+			MaxAge:      60,
+		},
+		{
+			Description: "old man",
+			Sex:         "man",
+			MinAge:      61,
+			MaxAge:      150,
+		},
+		{
+			Description: "old women",
+			Sex:         "women",
+			MinAge:      61,
+			MaxAge:      150,
+		},
+		{
+			Description: "unknown age-sex group",
+			Sex:         "unknown",
+			MinAge:      0,
+			MaxAge:      0,
+			IsDefault:   true,
+		},
+	}
+}
+
+func TestRepository_Groups(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			groups, defaultGroup, err := repo.GetGroups(ctx)
+			require.NoError(t, err)
+			require.Len(t, groups, len(testGroups()))
+			require.Equal(t, "unknown age-sex group", defaultGroup)
+
+			// the group of any age with rules isn't the default one.
+			require.NoError(t, repo.AddGroup(ctx, entities.Group{Description: "mobile"}, []entities.Rule{{Attribute: "device", Operator: entities.RuleEqual, Value: "mobile"}}))
+			_, defaultGroup, err = repo.GetGroups(ctx)
+			require.NoError(t, err)
+			require.Equal(t, "unknown age-sex group", defaultGroup)
+
+			group, err := repo.GetGroup(ctx, 45, "women")
+			require.NoError(t, err)
+			require.Equal(t, "middle-age women", group.Description)
+
+			_, err = repo.GetGroup(ctx, 45, "robot")
+			require.ErrorIs(t, err, entities.ErrNotFound)
+
+			require.ErrorIs(t, repo.AddGroup(ctx, *testGroups()[0], nil), entities.ErrAlreadyExists)
+			require.NoError(t, repo.AddGroup(ctx, entities.Group{Description: "vip", MinAge: 20, MaxAge: 30}, []entities.Rule{{Attribute: "plan", Operator: entities.RuleEqual, Value: "gold"}}))
+			rules, err := repo.GetGroupRules(ctx)
+			require.NoError(t, err)
+			require.Len(t, rules["vip"], 1)
+
+			require.NoError(t, repo.DeleteGroup(ctx, "vip"))
+			require.ErrorIs(t, repo.DeleteGroup(ctx, "vip"), entities.ErrNotFound)
+
+			// the group history isn't deleted with the group.
+			testAction(t, repo, "site.com", 1, 1, "old man", entities.Action{Shows: 1})
+			require.ErrorIs(t, repo.DeleteGroup(ctx, "old man"), entities.ErrFailedPrecondition)
+			actions, err := repo.GetActions(ctx, "site.com", 1, 1)
+			require.NoError(t, err)
+			require.Equal(t, uint(1), actions[*testGroups()[4]].Shows)
+		})
+	}
+}
+
+func TestRepository_UpdateKeepsHistory(t *testing.T) {
+	ctx := context.Background()
+	hour := time.Now().UTC().Truncate(time.Hour)
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			testAction(t, repo, "site.com", 1, 1, "old man", entities.Action{Clicks: 1, Shows: 3})
+
+			require.NoError(t, repo.UpdateSlot(ctx, "site.com", 1, "header", true, 728, 90))
+			require.NoError(t, repo.UpdateBanner(ctx, "site.com", 1, 1, "new sale", entities.Creative{Width: 728, Height: 90}))
+			slot, err := repo.GetSlot(ctx, "site.com", 1)
+			require.NoError(t, err)
+			require.Equal(t, entities.Slot{InnerID: 1, Description: "header", ViewableTry: true, Width: 728, Height: 90}, *slot)
+			banners, err := repo.GetBannersBySlotID(ctx, "site.com", 1)
+			require.NoError(t, err)
+			require.Equal(t, []entities.Banner{{InnerID: 1, Description: "new sale", Creative: entities.Creative{Width: 728, Height: 90}}}, banners)
+
+			actions, err := repo.GetActions(ctx, "site.com", 1, 1)
+			require.NoError(t, err)
+			require.Equal(t, entities.Action{Clicks: 1, Shows: 3}, actions[*testGroups()[4]])
+			buckets, err := repo.GetBuckets(ctx, "site.com", 1, 1, hour, hour.Add(time.Hour))
+			require.NoError(t, err)
+			require.Len(t, buckets, 1)
+			require.Equal(t, entities.Action{Clicks: 1, Shows: 3}, buckets[0].Action)
+
+			// events keep being added to the updated banner.
+			testAction(t, repo, "site.com", 1, 1, "old man", entities.Action{Shows: 1})
+			actions, err = repo.GetActions(ctx, "site.com", 1, 1)
+			require.NoError(t, err)
+			require.Equal(t, entities.Action{Clicks: 1, Shows: 4}, actions[*testGroups()[4]])
+		})
+	}
+}
+
+func TestRepository_SlotsAndBanners(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, repo.AddSlot(ctx, "site.com", 1, "top", false, 0, 0), entities.ErrAlreadyExists)
+			require.ErrorIs(t, repo.AddBannerToSlot(ctx, "site.com", 1, 1, "sale", entities.Creative{}), entities.ErrAlreadyExists)
+			require.ErrorIs(t, repo.AddSlot(ctx, "", 2, "top", false, 0, 0), entities.ErrInvalidArgument)
+
+			_, err := repo.GetSlotsByPageURL(ctx, "other.com")
+			require.ErrorIs(t, err, entities.ErrNotFound)
+			_, err = repo.GetBanner(ctx, "site.com", 1, 2)
+			require.ErrorIs(t, err, entities.ErrNotFound)
+
+			require.NoError(t, repo.UpdateBanner(ctx, "site.com", 1, 1, "new sale", entities.Creative{Width: 10}))
+			banner, err := repo.GetBanner(ctx, "site.com", 1, 1)
+			require.NoError(t, err)
+			require.Equal(t, "new sale", banner.Description)
+
+			// returned banner is a copy.
+			banner.Description = "changed"
+			banners, err := repo.GetBannersBySlotID(ctx, "site.com", 1)
+			require.NoError(t, err)
+			require.Equal(t, "new sale", banners[0].Description)
+
+			require.NoError(t, repo.DeleteBannerFromSlot(ctx, "site.com", 1, 1))
+			banners, err = repo.GetBannersBySlotID(ctx, "site.com", 1)
+			require.NoError(t, err)
+			require.Empty(t, banners)
+			require.ErrorIs(t, repo.SetBannerTags(ctx, 1, entities.Tags{}), entities.ErrNotFound)
+
+			require.NoError(t, repo.DeletePage(ctx, "site.com"))
+			pages, err := repo.GetPages(ctx)
+			require.NoError(t, err)
+			require.Empty(t, pages)
+		})
+	}
+}
+
+func TestRepository_Actions(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				testAction(t, repo, "site.com", 1, 1, "young man", entities.Action{Shows: 1})
+			}
+			testAction(t, repo, "site.com", 1, 1, "young man", entities.Action{Clicks: 1})
+			robots := entities.ActionIncrement{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "robots", Hour: time.Now(), Action: entities.Action{Clicks: 1}}
+			skipped, err := repo.AddActions(ctx, []entities.ActionIncrement{robots})
+			require.NoError(t, err)
+			require.Equal(t, []entities.ActionIncrement{robots}, skipped)
+
+			actions, err := repo.GetActions(ctx, "site.com", 1, 1)
+			require.NoError(t, err)
+			require.Len(t, actions, len(testGroups()))
+			require.Equal(t, entities.Action{Clicks: 1, Shows: 100}, actions[entities.Group{Description: "young man", Sex: "man", MaxAge: 40}])
+
+			buckets, err := repo.GetBuckets(ctx, "site.com", 0, 0, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+			require.NoError(t, err)
+			require.Len(t, buckets, 1)
+			require.Equal(t, entities.Action{Clicks: 1, Shows: 100}, buckets[0].Action)
+
+			require.NoError(t, repo.DeleteBucketsBefore(ctx, time.Now().Add(time.Hour)))
+			buckets, err = repo.GetBuckets(ctx, "site.com", 0, 0, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+			require.NoError(t, err)
+			require.Empty(t, buckets)
+		})
+	}
+}
+
+func TestRepository_History(t *testing.T) {
+	ctx := context.Background()
+	hour := time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+	increments := []entities.ActionIncrement{
+		{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "young man", Hour: hour, Action: entities.Action{Shows: 1}},
+		{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man", Hour: hour.Add(time.Hour), Action: entities.Action{Clicks: 1}},
+		{PageURL: "site.com", SlotID: 2, BannerID: 1, GroupDescription: "young man", Hour: hour.Add(2 * time.Hour), Action: entities.Action{Views: 1}},
+	}
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, repo.AddSlot(ctx, "site.com", 2, "bottom", false, 0, 0))
+			require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 2, 1, "sale", entities.Creative{}))
+			skipped, err := repo.AddActions(ctx, increments)
+			require.NoError(t, err)
+			require.Empty(t, skipped)
+
+			// the end of the range is excluded.
+			buckets, err := repo.GetBuckets(ctx, "site.com", 0, 0, hour, hour.Add(2*time.Hour))
+			require.NoError(t, err)
+			require.Len(t, buckets, 2)
+			require.True(t, hour.Equal(buckets[0].Start))
+			require.Equal(t, "young man", buckets[0].GroupDescription)
+			require.Equal(t, entities.Action{Shows: 1}, buckets[0].Action)
+			require.True(t, hour.Add(time.Hour).Equal(buckets[1].Start))
+			require.Equal(t, entities.Action{Clicks: 1}, buckets[1].Action)
+
+			buckets, err = repo.GetBuckets(ctx, "site.com", 2, 1, hour, hour.Add(3*time.Hour))
+			require.NoError(t, err)
+			require.Len(t, buckets, 1)
+			require.Equal(t, uint(2), buckets[0].SlotID)
+			require.Equal(t, entities.Action{Views: 1}, buckets[0].Action)
+
+			buckets, err = repo.GetBuckets(ctx, "other.com", 0, 0, hour, hour.Add(3*time.Hour))
+			require.NoError(t, err)
+			require.Empty(t, buckets)
+
+			// the bucket started at the time is kept.
+			require.NoError(t, repo.DeleteBucketsBefore(ctx, hour.Add(time.Hour)))
+			buckets, err = repo.GetBuckets(ctx, "site.com", 0, 0, hour, hour.Add(3*time.Hour))
+			require.NoError(t, err)
+			require.Len(t, buckets, 2)
+			require.True(t, hour.Add(time.Hour).Equal(buckets[0].Start))
+
+			// totals are kept without buckets.
+			actions, err := repo.GetActions(ctx, "site.com", 1, 1)
+			require.NoError(t, err)
+			require.Equal(t, entities.Action{Shows: 1}, actions[*testGroups()[0]])
+		})
+	}
+}
+
+func TestRepository_Catalog(t *testing.T) {
+	ctx := context.Background()
+	oldMan := entities.Group{Description: "old man", Sex: "man", MinAge: 61, MaxAge: 150}
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, repo.AddCatalogBanner(ctx, 2, "promo", entities.Creative{AssetURL: "promo.png"}))
+			require.ErrorIs(t, repo.AddCatalogBanner(ctx, 2, "promo", entities.Creative{}), entities.ErrAlreadyExists)
+			require.ErrorIs(t, repo.AttachBanner(ctx, "site.com", 1, 3), entities.ErrNotFound)
+			require.ErrorIs(t, repo.AttachBanner(ctx, "site.com", 2, 2), entities.ErrNotFound)
+			_, err := repo.GetBannerActions(ctx, 3)
+			require.ErrorIs(t, err, entities.ErrNotFound)
+
+			// the catalog banner runs in slots of different pages.
+			require.NoError(t, repo.AddSlot(ctx, "other.com", 1, "top", false, 0, 0))
+			require.NoError(t, repo.AttachBanner(ctx, "site.com", 1, 2))
+			require.ErrorIs(t, repo.AttachBanner(ctx, "site.com", 1, 2), entities.ErrAlreadyExists)
+			require.NoError(t, repo.AttachBanner(ctx, "other.com", 1, 2))
+			banners, err := repo.GetBannersBySlotID(ctx, "other.com", 1)
+			require.NoError(t, err)
+			require.Equal(t, []entities.Banner{{InnerID: 2, Description: "promo", Creative: entities.Creative{AssetURL: "promo.png"}}}, banners)
+
+			// the slot banner with the same inner id isn't counted.
+			require.NoError(t, repo.AddSlot(ctx, "other.com", 2, "bottom", false, 0, 0))
+			require.NoError(t, repo.AddBannerToSlot(ctx, "other.com", 2, 2, "sale", entities.Creative{}))
+			testAction(t, repo, "site.com", 1, 2, "old man", entities.Action{Clicks: 1, Shows: 2})
+			testAction(t, repo, "other.com", 1, 2, "old man", entities.Action{Shows: 3})
+			testAction(t, repo, "other.com", 2, 2, "old man", entities.Action{Shows: 100})
+			actions, err := repo.GetBannerActions(ctx, 2)
+			require.NoError(t, err)
+			require.Len(t, actions, len(testGroups()))
+			require.Equal(t, entities.Action{Clicks: 1, Shows: 5}, actions[oldMan])
+
+			// the attached banner isn't deleted with its stats.
+			require.ErrorIs(t, repo.DeleteCatalogBanner(ctx, 2), entities.ErrFailedPrecondition)
+			require.NoError(t, repo.DeleteBannerFromSlot(ctx, "site.com", 1, 2))
+			actions, err = repo.GetBannerActions(ctx, 2)
+			require.NoError(t, err)
+			require.Equal(t, entities.Action{Shows: 3}, actions[oldMan])
+			require.ErrorIs(t, repo.DeleteCatalogBanner(ctx, 2), entities.ErrFailedPrecondition)
+
+			// the catalog banner is kept when detached from all slots.
+			require.NoError(t, repo.DeleteBannerFromSlot(ctx, "other.com", 1, 2))
+			catalog, err := repo.GetCatalogBanners(ctx)
+			require.NoError(t, err)
+			require.Equal(t, []entities.Banner{{InnerID: 2, Description: "promo", Creative: entities.Creative{AssetURL: "promo.png"}}}, catalog)
+			actions, err = repo.GetBannerActions(ctx, 2)
+			require.NoError(t, err)
+			require.Equal(t, entities.Action{}, actions[oldMan])
+
+			require.NoError(t, repo.DeleteCatalogBanner(ctx, 2))
+			require.ErrorIs(t, repo.DeleteCatalogBanner(ctx, 2), entities.ErrNotFound)
+			_, err = repo.GetBannerActions(ctx, 2)
+			require.ErrorIs(t, err, entities.ErrNotFound)
+			// the slot banner with the same inner id is kept.
+			slotActions, err := repo.GetActions(ctx, "other.com", 2, 2)
+			require.NoError(t, err)
+			require.Equal(t, entities.Action{Shows: 100}, slotActions[oldMan])
+		})
+	}
+}
+
+func TestRepository_CatalogSlotBanner(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			// the banner added to the slot becomes the catalog banner with its stats.
+			testAction(t, repo, "site.com", 1, 1, "old man", entities.Action{Clicks: 1})
+			require.NoError(t, repo.AddCatalogBanner(ctx, 1, "sale", entities.Creative{}))
+			actions, err := repo.GetBannerActions(ctx, 1)
+			require.NoError(t, err)
+			require.Equal(t, uint(1), actions[entities.Group{Description: "old man", Sex: "man", MinAge: 61, MaxAge: 150}].Clicks)
+
+			require.NoError(t, repo.AddSlot(ctx, "site.com", 2, "bottom", false, 0, 0))
+			require.NoError(t, repo.AttachBanner(ctx, "site.com", 2, 1))
+			banners, err := repo.GetBannersBySlotID(ctx, "site.com", 2)
+			require.NoError(t, err)
+			require.Equal(t, []entities.Banner{{InnerID: 1, Description: "sale"}}, banners)
+		})
+	}
+}
+
+func TestRepository_SharedBanner(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			creative := entities.Creative{AssetURL: "https://cdn.site.com/sale.png", Width: 300, Height: 250}
+			require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 1, 2, "spring", creative))
+			require.NoError(t, repo.AddSlot(ctx, "site.com", 2, "bottom", false, 0, 0))
+			require.ErrorIs(t, repo.AddBannerToSlot(ctx, "site.com", 2, 2, "spring", entities.Creative{Width: 300}), entities.ErrAlreadyExists)
+			require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 2, 2, "spring", creative))
+
+			for _, slotID := range []uint{1, 2} {
+				banner, err := repo.GetBanner(ctx, "site.com", slotID, 2)
+				require.NoError(t, err)
+				require.Equal(t, creative, banner.Creative)
+			}
+		})
+	}
+}
+
+func TestRepository_Campaigns(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, repo.AddCampaign(ctx, "acme", "spring"), entities.ErrNotFound)
+			require.NoError(t, repo.AddAdvertiser(ctx, "acme"))
+			require.ErrorIs(t, repo.AddAdvertiser(ctx, "acme"), entities.ErrAlreadyExists)
+			require.NoError(t, repo.AddCampaign(ctx, "acme", "spring"))
+			require.NoError(t, repo.AddBannerToCampaign(ctx, "spring", 1))
+			require.ErrorIs(t, repo.AddBannerToCampaign(ctx, "spring", 2), entities.ErrNotFound)
+			// banners with the same inner id and different descriptions can't be told apart.
+			require.NoError(t, repo.AddSlot(ctx, "site.com", 2, "bottom", false, 0, 0))
+			require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 1, 2, "winter", entities.Creative{}))
+			require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 2, 2, "summer", entities.Creative{}))
+			require.ErrorIs(t, repo.AddBannerToCampaign(ctx, "spring", 2), entities.ErrInvalidArgument)
+			testAction(t, repo, "site.com", 1, 1, "old man", entities.Action{Clicks: 1})
+
+			require.NoError(t, repo.SetAdvertiserPaused(ctx, "acme", true))
+			paused, err := repo.GetPausedBanners(ctx)
+			require.NoError(t, err)
+			require.Equal(t, []entities.Banner{{InnerID: 1, Description: "sale"}}, paused)
+
+			actions, err := repo.GetAdvertiserActions(ctx, "acme")
+			require.NoError(t, err)
+			require.Equal(t, uint(1), actions[entities.Group{Description: "old man", Sex: "man", MinAge: 61, MaxAge: 150}].Clicks)
+		})
+	}
+}
+
+func TestMemRepo_ConcurrentActions(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepositories(t)["memory"]
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			testAction(t, repo, "site.com", 1, 1, "young man", entities.Action{Shows: 1})
+		}()
+	}
+	wg.Wait()
+	actions, err := repo.GetActions(ctx, "site.com", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint(100), actions[entities.Group{Description: "young man", Sex: "man", MaxAge: 40}].Shows)
+}
+
+func TestRepository_PageTrees(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, repo.AddSlot(ctx, "site.com", 2, "bottom", true, 0, 0))
+			require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 2, 1, "sale", entities.Creative{}))
+			require.NoError(t, repo.AddBannerToSlot(ctx, "site.com", 2, 2, "promo", entities.Creative{AssetURL: "promo.png", Width: 100}))
+			require.NoError(t, repo.AddSlot(ctx, "other.com", 1, "top", false, 0, 0))
+			testAction(t, repo, "site.com", 2, 2, "old man", entities.Action{Clicks: 1})
+			testAction(t, repo, "site.com", 1, 1, "old man", entities.Action{Shows: 1})
+
+			trees, err := repo.GetPageTrees(ctx, "site.com")
+			require.NoError(t, err)
+			require.Len(t, trees, 1)
+			require.Equal(t, "site.com", trees[0].URL)
+			require.Len(t, trees[0].Slots, 2)
+			require.Equal(t, uint(2), trees[0].Slots[1].InnerID)
+			require.True(t, trees[0].Slots[1].ViewableTry)
+
+			banners := trees[0].Slots[1].Banners
+			require.Len(t, banners, 2)
+			require.Equal(t, entities.Banner{InnerID: 2, Description: "promo", Creative: entities.Creative{AssetURL: "promo.png", Width: 100}}, banners[1].Banner)
+			oldMan := entities.Group{Description: "old man", Sex: "man", MinAge: 61, MaxAge: 150}
+			require.Len(t, banners[1].Actions, len(testGroups()))
+			require.Equal(t, entities.Action{Clicks: 1}, banners[1].Actions[oldMan])
+			require.Equal(t, entities.Action{}, banners[0].Actions[oldMan])
+			require.Equal(t, entities.Action{Shows: 1}, trees[0].Slots[0].Banners[0].Actions[oldMan])
+
+			trees, err = repo.GetPageTrees(ctx, "")
+			require.NoError(t, err)
+			require.Len(t, trees, 2)
+			require.Empty(t, trees[1].Slots[0].Banners)
+
+			_, err = repo.GetPageTrees(ctx, "unknown.com")
+			require.ErrorIs(t, err, entities.ErrNotFound)
+		})
+	}
+}
+
+func TestRepository_AddActions(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range testRepositories(t) {
+		repo := repo
+		t.Run(name, func(t *testing.T) {
+			hour := time.Now().UTC().Truncate(time.Hour)
+			increments := []entities.ActionIncrement{
+				{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man", Hour: hour, Action: entities.Action{Clicks: 1, Shows: 10}},
+				{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man", Hour: hour.Add(-time.Hour), Action: entities.Action{Shows: 5}},
+				{PageURL: "site.com", SlotID: 1, BannerID: 2, GroupDescription: "old man", Hour: hour, Action: entities.Action{Shows: 1}},
+				{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "robots", Hour: hour, Action: entities.Action{Shows: 1}},
+			}
+			skipped, err := repo.AddActions(ctx, increments)
+			require.NoError(t, err)
+			require.Equal(t, increments[2:], skipped)
+			_, err = repo.AddActions(ctx, increments[:1])
+			require.NoError(t, err)
+
+			oldMan := entities.Group{Description: "old man", Sex: "man", MinAge: 61, MaxAge: 150}
+			actions, err := repo.GetActions(ctx, "site.com", 1, 1)
+			require.NoError(t, err)
+			require.Equal(t, entities.Action{Clicks: 2, Shows: 25}, actions[oldMan])
+
+			buckets, err := repo.GetBuckets(ctx, "site.com", 1, 1, hour.Add(-time.Hour), hour.Add(time.Hour))
+			require.NoError(t, err)
+			require.Len(t, buckets, 2)
+			require.Equal(t, entities.Action{Shows: 5}, buckets[0].Action)
+			require.Equal(t, entities.Action{Clicks: 2, Shows: 20}, buckets[1].Action)
+
+			_, err = repo.AddActions(ctx, []entities.ActionIncrement{{PageURL: "site.com", SlotID: 1, BannerID: 1}})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPGRepo_CanceledContext(t *testing.T) {
+	repo := testRepositories(t)["sqlite"]
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.GetSlotsByPageURL(ctx, "site.com")
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = repo.AddActions(ctx, []entities.ActionIncrement{{PageURL: "site.com", SlotID: 1, BannerID: 1, GroupDescription: "old man", Action: entities.Action{Shows: 1}}})
+	require.ErrorIs(t, err, context.Canceled)
+
+	slots, err := repo.GetSlotsByPageURL(context.Background(), "site.com")
+	require.NoError(t, err)
+	require.Len(t, slots, 1)
+}
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS banner_tags;
+DROP TABLE IF EXISTS banner_targetings;
+DROP TABLE IF EXISTS banner_event_buckets;
+DROP TABLE IF EXISTS banner_events;
+DROP TABLE IF EXISTS group_rules;
+DROP TABLE IF EXISTS "groups";
+DROP TABLE IF EXISTS banner_slots;
+DROP TABLE IF EXISTS banners;
+DROP TABLE IF EXISTS campaigns;
+DROP TABLE IF EXISTS advertisers;
+DROP TABLE IF EXISTS slots;
+DROP TABLE IF EXISTS pages;
@@ -0,0 +1,157 @@
+-- tables are created if not exist to adopt databases created by gorm AutoMigrate,
+-- columns added to these tables later are added by adoptColumns before this migration.
+CREATE TABLE IF NOT EXISTS pages (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    url varchar(255) NOT NULL UNIQUE,
+    algorithm varchar(255),
+    allowed_categories varchar(255)
+);
+CREATE INDEX IF NOT EXISTS idx_pages_deleted_at ON pages (deleted_at);
+
+CREATE TABLE IF NOT EXISTS slots (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    page_id integer NOT NULL,
+    inner_id integer NOT NULL,
+    description varchar(255),
+    viewable_try boolean NOT NULL DEFAULT false,
+    width integer,
+    height integer
+);
+CREATE INDEX IF NOT EXISTS idx_slots_deleted_at ON slots (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS innerid_pageid ON slots (page_id, inner_id);
+
+CREATE TABLE IF NOT EXISTS advertisers (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    name varchar(255) NOT NULL UNIQUE
+);
+CREATE INDEX IF NOT EXISTS idx_advertisers_deleted_at ON advertisers (deleted_at);
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    advertiser_id integer NOT NULL,
+    name varchar(255) NOT NULL UNIQUE,
+    paused boolean NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS idx_campaigns_deleted_at ON campaigns (deleted_at);
+
+CREATE TABLE IF NOT EXISTS banners (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    campaign_id integer,
+    catalog boolean NOT NULL DEFAULT false,
+    inner_id integer NOT NULL,
+    description varchar(255) NOT NULL,
+    asset_url varchar(255),
+    width integer,
+    height integer,
+    alt_text varchar(255),
+    target_url varchar(255),
+    mime_type varchar(255)
+);
+CREATE INDEX IF NOT EXISTS idx_banners_deleted_at ON banners (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS innerid_description ON banners (inner_id, description);
+
+CREATE TABLE IF NOT EXISTS banner_slots (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    banner_id integer NOT NULL,
+    slot_id integer NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_banner_slots_deleted_at ON banner_slots (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS "BannerID_SlotID" ON banner_slots (banner_id, slot_id);
+
+CREATE TABLE IF NOT EXISTS "groups" (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    description varchar(255) NOT NULL,
+    sex varchar(255) NOT NULL,
+    min_age integer NOT NULL,
+    max_age integer NOT NULL,
+    priority integer NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_groups_deleted_at ON "groups" (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS des_sex ON "groups" (description, sex, min_age, max_age);
+
+CREATE TABLE IF NOT EXISTS group_rules (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    group_id integer NOT NULL,
+    attribute varchar(255) NOT NULL,
+    operator varchar(255) NOT NULL,
+    value varchar(255)
+);
+CREATE INDEX IF NOT EXISTS idx_group_rules_deleted_at ON group_rules (deleted_at);
+
+CREATE TABLE IF NOT EXISTS banner_events (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    clicks integer,
+    shows integer,
+    views integer,
+    banner_slot_id integer NOT NULL,
+    group_id integer NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_banner_events_deleted_at ON banner_events (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS "BannerSlotID_GroupID" ON banner_events (banner_slot_id, group_id);
+
+CREATE TABLE IF NOT EXISTS banner_event_buckets (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    clicks integer,
+    shows integer,
+    views integer,
+    banner_slot_id integer NOT NULL,
+    group_id integer NOT NULL,
+    hour timestamp with time zone NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_banner_event_buckets_deleted_at ON banner_event_buckets (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS "BannerSlotID_GroupID_Hour" ON banner_event_buckets (banner_slot_id, group_id, hour);
+
+CREATE TABLE IF NOT EXISTS banner_targetings (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    banner_inner_id integer NOT NULL UNIQUE,
+    min_age integer,
+    max_age integer,
+    sex varchar(255),
+    include_groups varchar(255),
+    exclude_groups varchar(255)
+);
+CREATE INDEX IF NOT EXISTS idx_banner_targetings_deleted_at ON banner_targetings (deleted_at);
+
+CREATE TABLE IF NOT EXISTS banner_tags (
+    id serial PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    banner_inner_id integer NOT NULL UNIQUE,
+    categories varchar(255),
+    competitors varchar(255)
+);
+CREATE INDEX IF NOT EXISTS idx_banner_tags_deleted_at ON banner_tags (deleted_at);
@@ -0,0 +1,5 @@
+ALTER TABLE "groups" ADD COLUMN IF NOT EXISTS is_default boolean NOT NULL DEFAULT false;
+-- the zero age range without rules marked the default group before.
+UPDATE "groups" SET is_default = true
+WHERE min_age = 0 AND max_age = 0 AND deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM group_rules WHERE group_rules.group_id = "groups".id);
@@ -0,0 +1,18 @@
+-- sqlite can't drop columns, the table is copied without the column.
+CREATE TABLE groups_without_default (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    description varchar(255) NOT NULL,
+    sex varchar(255) NOT NULL,
+    min_age integer NOT NULL,
+    max_age integer NOT NULL,
+    priority integer NOT NULL DEFAULT 0
+);
+INSERT INTO groups_without_default (id, created_at, updated_at, deleted_at, description, sex, min_age, max_age, priority)
+SELECT id, created_at, updated_at, deleted_at, description, sex, min_age, max_age, priority FROM "groups";
+DROP TABLE "groups";
+ALTER TABLE groups_without_default RENAME TO "groups";
+CREATE INDEX IF NOT EXISTS idx_groups_deleted_at ON "groups" (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS des_sex ON "groups" (description, sex, min_age, max_age);
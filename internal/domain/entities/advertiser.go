@@ -0,0 +1,15 @@
+package entities
+
+import "context"
+
+type Advertiser struct {
+	Name string `gorm:"UNIQUE; NOT NULL"`
+}
+
+type AdvertiserRepository interface {
+	AddAdvertiser(ctx context.Context, advertiserName string) error
+	GetAdvertisers(ctx context.Context) (advertisers []Advertiser, err error)
+	// SetAdvertiserPaused pauses or resumes all campaigns of the advertiser.
+	SetAdvertiserPaused(ctx context.Context, advertiserName string, paused bool) error
+	GetAdvertiserActions(ctx context.Context, advertiserName string) (actions map[Group]Action, err error)
+}
@@ -1,13 +1,44 @@
 package entities
 
+import "context"
+
 type Banner struct {
 	InnerID     uint   `gorm:"UNIQUE_INDEX:innerid_description; NOT NULL"`
 	Description string `gorm:"UNIQUE_INDEX:innerid_description; NOT NULL"`
+	Creative
+}
+
+// Creative describes how a client renders the banner.
+type Creative struct {
+	AssetURL  string
+	Width     uint
+	Height    uint
+	AltText   string
+	TargetURL string
+	MIMEType  string
 }
 
 type BannerRepository interface {
-	AddBannerToSlot(pageURL string, slotInnerID uint, bannerInnerID uint, bannerDescription string) error
-	DeleteBannerFromSlot(pageURL string, slotInnerID, bannerInnerID uint) error
-	DeleteAllBannersFormSlot(pageURL string, slotInnerID uint) error
-	GetBannersBySlotID(pageURL string, slotInnerID uint) (banners []Banner, err error)
+	// AddBannerToSlot adds the existing banner with the same id and description to the slot,
+	// it fails if the creative of the existing banner differs.
+	AddBannerToSlot(ctx context.Context, pageURL string, slotInnerID uint, bannerInnerID uint, bannerDescription string, creative Creative) error
+	DeleteBannerFromSlot(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) error
+	DeleteAllBannersFormSlot(ctx context.Context, pageURL string, slotInnerID uint) error
+	GetBannersBySlotID(ctx context.Context, pageURL string, slotInnerID uint) (banners []Banner, err error)
+	// UpdateBanner changes metadata of the banner in every slot it is attached to,
+	// the description is a part of the banner key, so the banner is renamed in all its slots.
+	// Slot relations and events are kept.
+	UpdateBanner(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint, bannerDescription string, creative Creative) error
+	GetBanner(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) (banner *Banner, err error)
+}
+
+// BannerCatalogRepository keeps banners which are created once and attached to many slots.
+type BannerCatalogRepository interface {
+	AddCatalogBanner(ctx context.Context, bannerInnerID uint, bannerDescription string, creative Creative) error
+	GetCatalogBanners(ctx context.Context) (banners []Banner, err error)
+	// DeleteCatalogBanner detaches the banner from all slots and deletes it.
+	DeleteCatalogBanner(ctx context.Context, bannerInnerID uint) error
+	AttachBanner(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) error
+	// GetBannerActions returns banner actions summed over all slots by user group.
+	GetBannerActions(ctx context.Context, bannerInnerID uint) (actions map[Group]Action, err error)
 }
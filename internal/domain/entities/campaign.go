@@ -0,0 +1,19 @@
+package entities
+
+import "context"
+
+type Campaign struct {
+	Name   string `gorm:"UNIQUE; NOT NULL"`
+	Paused bool   `gorm:"NOT NULL; DEFAULT:false"`
+}
+
+type CampaignRepository interface {
+	AddCampaign(ctx context.Context, advertiserName, campaignName string) error
+	GetCampaigns(ctx context.Context, advertiserName string) (campaigns []Campaign, err error)
+	// AddBannerToCampaign fails if banners with different descriptions share the inner id.
+	AddBannerToCampaign(ctx context.Context, campaignName string, bannerInnerID uint) error
+	SetCampaignPaused(ctx context.Context, campaignName string, paused bool) error
+	GetCampaignActions(ctx context.Context, campaignName string) (actions map[Group]Action, err error)
+	// GetPausedBanners returns banners which belong to paused campaigns.
+	GetPausedBanners(ctx context.Context) (banners []Banner, err error)
+}
@@ -1,7 +1,162 @@
 package entities
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of domain errors, errors of a kind match it with errors.Is after errors.Wrapf.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrAlreadyExists   = errors.New("already exists")
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrSchemaOutOfDate = errors.New("schema out of date")
+	// ErrFailedPrecondition means the object can't be changed in its current state.
+	ErrFailedPrecondition = errors.New("failed precondition")
+)
+
+// Error is the domain error of the Kind.
+type Error struct {
+	Kind error
+	Mess string
+}
+
+func (e *Error) Error() string {
+	return e.Mess
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func newError(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Mess: fmt.Sprintf(format, args...)}
+}
+
+func ErrZeroValue() error {
+	return newError(ErrInvalidArgument, "params has zerovalues")
+}
+
+func ErrPageExist(pageURL string) error {
+	return newError(ErrAlreadyExists, "Page %v exist", pageURL)
+}
+
+func ErrPageNotFound(pageURL string) error {
+	return newError(ErrNotFound, "Page %v not found", pageURL)
+}
+
+func ErrSlotExist(slotID uint, pageURL string) error {
+	return newError(ErrAlreadyExists, "Slot with id %v on page %v exist", slotID, pageURL)
+}
+
+func ErrSlotNotFound(slotID uint, pageURL string) error {
+	return newError(ErrNotFound, "Slot with id %v on page %v not found", slotID, pageURL)
+}
+
+func ErrBannerNotFound(slotID, bannerID uint, pageURL string) error {
+	return newError(ErrNotFound, "Banner with id %v for slot %v on page %v not found", bannerID, slotID, pageURL)
+}
 
 func ErrBannerExist(slotID, bannerID uint, pageURL, bannerDescription string) error {
-	return fmt.Errorf("Banner for slot %v on page %v  with id %v or description %v exist", slotID, pageURL, bannerID, bannerDescription)
+	return newError(ErrAlreadyExists, "Banner for slot %v on page %v  with id %v or description %v exist", slotID, pageURL, bannerID, bannerDescription)
+}
+
+func ErrBannerCreativeExist(bannerID uint, bannerDescription string) error {
+	return newError(ErrAlreadyExists, "Banner with id %v and description %v exist with another creative", bannerID, bannerDescription)
+}
+
+func ErrCreativeNotFit(slotID, bannerID uint, pageURL string, width, height uint) error {
+	return newError(ErrInvalidArgument, "Banner with id %v size %vx%v doesn't fit slot %v on page %v", bannerID, width, height, slotID, pageURL)
+}
+
+func ErrGroupExist(groupDescription string) error {
+	return newError(ErrAlreadyExists, "Group with description %v exist", groupDescription)
+}
+
+func ErrGroupHasEvents(groupDescription string) error {
+	return newError(ErrFailedPrecondition, "Group %v has events, its history can't be deleted", groupDescription)
+}
+
+func ErrDefaultGroupExist(groupDescription string) error {
+	return newError(ErrAlreadyExists, "Group %v is already the default group", groupDescription)
+}
+
+func ErrGroupOverlap(groupDescription, otherDescription string) error {
+	return newError(ErrInvalidArgument, "Group %v overlaps age range of group %v", groupDescription, otherDescription)
+}
+
+func ErrGroupAgeRange(groupDescription string, minAge, maxAge uint) error {
+	return newError(ErrInvalidArgument, "Group %v has invalid age range %v-%v", groupDescription, minAge, maxAge)
+}
+
+func ErrInvalidRule(attribute, operator string) error {
+	return newError(ErrInvalidArgument, "Rule for attribute %v with operator %v is invalid", attribute, operator)
+}
+
+func ErrGroupNotFound(groupDescription string) error {
+	return newError(ErrNotFound, "Group with description %v not found", groupDescription)
+}
+
+func ErrTargetingAgeRange(minAge, maxAge uint) error {
+	return newError(ErrInvalidArgument, "Targeting has invalid age range %v-%v", minAge, maxAge)
+}
+
+func ErrUnknownAlgorithm(algorithm string) error {
+	return newError(ErrInvalidArgument, "Algorithm %v is unknown", algorithm)
+}
+
+func ErrCatalogBannerExist(bannerID uint) error {
+	return newError(ErrAlreadyExists, "Banner with id %v exist in catalog", bannerID)
+}
+
+func ErrInvalidGranularity(granularity string) error {
+	return newError(ErrInvalidArgument, "Granularity %v is invalid, use hour or day", granularity)
+}
+
+func ErrUnknownEventType(eventType string) error {
+	return newError(ErrInvalidArgument, "Event type %v is unknown", eventType)
+}
+
+func ErrInvalidStatDimension(dimension string) error {
+	return newError(ErrInvalidArgument, "Stat dimension %v is invalid, use slot, banner or group", dimension)
+}
+
+func ErrInvalidConfidence(confidence float64) error {
+	return newError(ErrInvalidArgument, "Confidence level %v is invalid, use value in (0, 1)", confidence)
+}
+
+func ErrGroupForUserNotFound(userAge uint, userSex string) error {
+	return newError(ErrNotFound, "Group for user with age %v and sex %v not found", userAge, userSex)
+}
+
+func ErrBannerIDNotFound(bannerID uint) error {
+	return newError(ErrNotFound, "Banner with id %v not found", bannerID)
+}
+
+func ErrBannerIDAmbiguous(bannerID uint) error {
+	return newError(ErrInvalidArgument, "Banner id %v is shared by banners with different descriptions", bannerID)
+}
+
+func ErrCatalogBannerNotFound(bannerID uint) error {
+	return newError(ErrNotFound, "Banner with id %v not found in catalog", bannerID)
+}
+
+func ErrCatalogBannerAttached(bannerID uint) error {
+	return newError(ErrFailedPrecondition, "Banner with id %v is attached to slots, detach it first", bannerID)
+}
+
+func ErrAdvertiserExist(advertiserName string) error {
+	return newError(ErrAlreadyExists, "Advertiser %v exist", advertiserName)
+}
+
+func ErrAdvertiserNotFound(advertiserName string) error {
+	return newError(ErrNotFound, "Advertiser %v not found", advertiserName)
+}
+
+func ErrCampaignExist(campaignName string) error {
+	return newError(ErrAlreadyExists, "Campaign %v exist", campaignName)
+}
+
+func ErrCampaignNotFound(campaignName string) error {
+	return newError(ErrNotFound, "Campaign %v not found", campaignName)
 }
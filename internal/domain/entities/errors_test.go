@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError_Kind(t *testing.T) {
+	tcases := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{name: "not found", err: ErrSlotNotFound(1, "site.com"), kind: ErrNotFound},
+		{name: "already exists", err: ErrGroupExist("old man"), kind: ErrAlreadyExists},
+		{name: "invalid argument", err: ErrZeroValue(), kind: ErrInvalidArgument},
+		{name: "failed precondition", err: ErrCatalogBannerAttached(1), kind: ErrFailedPrecondition},
+		{name: "invalid rule", err: ValidateRules([]Rule{{Attribute: "device", Operator: "like"}}), kind: ErrInvalidArgument},
+	}
+	for _, tcase := range tcases {
+		tcase := tcase
+		t.Run(tcase.name, func(t *testing.T) {
+			err := errors.Wrapf(tcase.err, "can't do %v", tcase.name)
+			require.ErrorIs(t, err, tcase.kind)
+			for _, other := range []error{ErrNotFound, ErrAlreadyExists, ErrInvalidArgument, ErrSchemaOutOfDate, ErrFailedPrecondition} {
+				if other != tcase.kind {
+					require.NotErrorIs(t, err, other)
+				}
+			}
+			var domainErr *Error
+			require.True(t, errors.As(err, &domainErr))
+			require.Equal(t, tcase.err.Error(), domainErr.Error())
+		})
+	}
+}
@@ -11,9 +11,22 @@ type Event struct {
 	PageURL                   string
 	SlotID, BannerID, UserAge uint
 	UserSex                   string
+	// GroupDescription is the user group resolved by the rotator.
+	GroupDescription string
+}
+
+// QueuedEvent is the event pulled from the queue with its position in the partition.
+type QueuedEvent struct {
+	Event
+	Partition int
+	Offset    int64
 }
 
 type EventQueue interface {
-	Pull(context context.Context, events chan<- Event) error
-	Push(Event) error
+	// Pull sends events to the channel until ctx is done,
+	// events that aren't committed are pulled again after restart.
+	Pull(ctx context.Context, events chan<- QueuedEvent) error
+	// Commit marks events and all previous events of their partitions as processed.
+	Commit(ctx context.Context, events []QueuedEvent) error
+	Push(ctx context.Context, event Event) error
 }
@@ -1,22 +1,120 @@
 package entities
 
+import (
+	"context"
+	"time"
+)
+
 type Group struct {
 	Description string `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
-	Sex         string `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
-	MinAge      uint   `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
-	MaxAge      uint   `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
+	// Sex is empty for groups which match any sex.
+	Sex    string `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
+	MinAge uint   `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
+	MaxAge uint   `gorm:"UNIQUE_INDEX:des_sex; NOT NULL"`
+	// Priority orders group matching, groups with greater priority are matched first.
+	Priority int `gorm:"NOT NULL; DEFAULT:0"`
+	// IsDefault group is given to users which match no other group.
+	IsDefault bool `gorm:"NOT NULL; DEFAULT:false"`
+}
+
+// ageRange returns the group age range, zero ages match any age.
+func (g Group) ageRange() (minAge, maxAge uint) {
+	if g.MinAge == 0 && g.MaxAge == 0 {
+		return 0, ^uint(0)
+	}
+	return g.MinAge, g.MaxAge
+}
+
+// Match reports whether the user age and sex belong to the group.
+func (g Group) Match(userAge uint, userSex string) bool {
+	minAge, maxAge := g.ageRange()
+	return (g.Sex == "" || g.Sex == userSex) && minAge <= userAge && maxAge >= userAge
+}
+
+// Overlaps reports whether both groups can match the same sex and have intersected age ranges.
+func (g Group) Overlaps(other Group) bool {
+	minAge, maxAge := g.ageRange()
+	otherMinAge, otherMaxAge := other.ageRange()
+	sameSex := g.Sex == "" || other.Sex == "" || g.Sex == other.Sex
+	return sameSex && minAge <= otherMaxAge && otherMinAge <= maxAge
+}
+
+// ValidateGroup checks the group against the other groups which already exist.
+// Age ranges per sex must not overlap for groups without targeting rules,
+// the default group isn't matched, so it overlaps nothing, and there is only one.
+func ValidateGroup(group Group, rules []Rule, groups []Group, groupRules map[string][]Rule) error {
+	if group.MinAge > group.MaxAge {
+		return ErrGroupAgeRange(group.Description, group.MinAge, group.MaxAge)
+	}
+	if err := ValidateRules(rules); err != nil {
+		return err
+	}
+	for _, other := range groups {
+		if other.Description == group.Description {
+			return ErrGroupExist(group.Description)
+		}
+		if group.IsDefault && other.IsDefault {
+			return ErrDefaultGroupExist(other.Description)
+		}
+		if group.IsDefault || other.IsDefault {
+			continue
+		}
+		if len(rules) == 0 && len(groupRules[other.Description]) == 0 && group.Overlaps(other) {
+			return ErrGroupOverlap(group.Description, other.Description)
+		}
+	}
+	return nil
 }
+
 type GroupRepository interface {
-	GetGroups() (groups []Group, defaultGroupDescription string, err error)
-	GetGroup(userAge uint, userSex string) (group *Group, err error)
+	GetGroups(ctx context.Context) (groups []Group, defaultGroupDescription string, err error)
+	GetGroup(ctx context.Context, userAge uint, userSex string) (group *Group, err error)
+	// GetGroupRules returns targeting rules of groups by group description.
+	GetGroupRules(ctx context.Context) (rules map[string][]Rule, err error)
+	AddGroup(ctx context.Context, group Group, rules []Rule) error
+	UpdateGroup(ctx context.Context, groupDescription string, group Group, rules []Rule) error
+	// DeleteGroup deletes the group with its rules, groups with events are kept.
+	DeleteGroup(ctx context.Context, groupDescription string) error
 }
 type Action struct {
 	Clicks uint
 	Shows  uint
+	Views  uint
+}
+
+// Add counts the event of the type ("click", "show" or "view").
+func (a *Action) Add(eventType string) error {
+	switch eventType {
+	case "click":
+		a.Clicks++
+	case "show":
+		a.Shows++
+	case "view":
+		a.Views++
+	default:
+		return ErrUnknownEventType(eventType)
+	}
+	return nil
+}
+
+// ActionIncrement is the sum of events of the banner in the slot for the user group during the hour.
+type ActionIncrement struct {
+	PageURL          string
+	SlotID           uint
+	BannerID         uint
+	GroupDescription string
+	// Hour is the start of the hourly statistics bucket.
+	Hour time.Time
+	Action
+}
+
+// ActionBatchRepository writes aggregated events at once.
+type ActionBatchRepository interface {
+	// AddActions adds increments to banner actions and their hourly buckets in one transaction,
+	// increments of deleted banners or groups are skipped and returned.
+	AddActions(ctx context.Context, increments []ActionIncrement) (skipped []ActionIncrement, err error)
 }
 
 type ActionRepository interface {
-	AddClickAction(pageURL string, slotInnerID, bannerInnerID, userAge uint, userSex string) error
-	AddShowAction(pageURL string, slotInnerID, bannerInnerID, userAge uint, userSex string) error
-	GetActions(pageURL string, slotInnerID, bannerInnerID uint) (clicks map[Group]Action, err error)
+	GetActions(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint) (clicks map[Group]Action, err error)
 }
@@ -0,0 +1,89 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateGroup(t *testing.T) {
+	groups := []Group{
+		{Description: "young man", Sex: "man", MinAge: 0, MaxAge: 40},
+		{Description: "middle-age man", Sex: "man", MinAge: 41, MaxAge: 60},
+		{Description: "young women", Sex: "women", MinAge: 0, MaxAge: 40},
+		{Description: "unknown", Sex: "unknown", IsDefault: true},
+	}
+	groupRules := map[string][]Rule{}
+	tcases := []struct {
+		name  string
+		group Group
+		rules []Rule
+		err   bool
+	}{
+		{name: "good", group: Group{Description: "old man", Sex: "man", MinAge: 61, MaxAge: 150}},
+		{name: "other sex", group: Group{Description: "middle-age women", Sex: "women", MinAge: 41, MaxAge: 60}},
+		{name: "overlap", group: Group{Description: "old man", Sex: "man", MinAge: 60, MaxAge: 150}, err: true},
+		{name: "duplicate", group: Group{Description: "young man", Sex: "man", MinAge: 151, MaxAge: 160}, err: true},
+		{name: "bad range", group: Group{Description: "old man", Sex: "man", MinAge: 150, MaxAge: 61}, err: true},
+		{name: "segment overlap", group: Group{Description: "mobile man", Sex: "man", MinAge: 0, MaxAge: 150}, rules: []Rule{{Attribute: "device", Operator: RuleEqual, Value: "mobile"}}},
+		{name: "any sex overlap", group: Group{Description: "old people", MinAge: 30, MaxAge: 150}, err: true},
+		{name: "any age overlap", group: Group{Description: "all men", Sex: "man"}, err: true},
+		{name: "zero ages with rules", group: Group{Description: "mobile"}, rules: []Rule{{Attribute: "device", Operator: RuleEqual, Value: "mobile"}}},
+		{name: "second default", group: Group{Description: "nobody", Sex: "nobody", IsDefault: true}, err: true},
+		{name: "bad rule", group: Group{Description: "mobile man", Sex: "man", MinAge: 0, MaxAge: 150}, rules: []Rule{{Attribute: "device", Operator: "like", Value: "mobile"}}, err: true},
+	}
+	for _, tcase := range tcases {
+		tcase := tcase
+		t.Run(tcase.name, func(t *testing.T) {
+			err := ValidateGroup(tcase.group, tcase.rules, groups, groupRules)
+			if tcase.err {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestGroup_Match(t *testing.T) {
+	tcases := []struct {
+		name  string
+		group Group
+		match bool
+	}{
+		{name: "age range", group: Group{Sex: "man", MinAge: 20, MaxAge: 40}, match: true},
+		{name: "other sex", group: Group{Sex: "women", MinAge: 20, MaxAge: 40}, match: false},
+		{name: "other age", group: Group{Sex: "man", MinAge: 41, MaxAge: 60}, match: false},
+		{name: "any age", group: Group{Sex: "man"}, match: true},
+		{name: "any sex and age", group: Group{}, match: true},
+	}
+	for _, tcase := range tcases {
+		tcase := tcase
+		t.Run(tcase.name, func(t *testing.T) {
+			require.Equal(t, tcase.match, tcase.group.Match(30, "man"))
+		})
+	}
+}
+
+func TestRule_Match(t *testing.T) {
+	attributes := map[string]string{"country": "ru", "referrer": "https://google.com/search"}
+	tcases := []struct {
+		name  string
+		rule  Rule
+		match bool
+	}{
+		{name: "eq", rule: Rule{Attribute: "country", Operator: RuleEqual, Value: "ru"}, match: true},
+		{name: "eq absent", rule: Rule{Attribute: "device", Operator: RuleEqual, Value: "ru"}, match: false},
+		{name: "neq", rule: Rule{Attribute: "country", Operator: RuleNotEqual, Value: "us"}, match: true},
+		{name: "in", rule: Rule{Attribute: "country", Operator: RuleIn, Value: "us, ru"}, match: true},
+		{name: "not in", rule: Rule{Attribute: "country", Operator: RuleIn, Value: "us,de"}, match: false},
+		{name: "prefix", rule: Rule{Attribute: "referrer", Operator: RulePrefix, Value: "https://google."}, match: true},
+		{name: "unknown operator", rule: Rule{Attribute: "country", Operator: "like", Value: "ru"}, match: false},
+	}
+	for _, tcase := range tcases {
+		tcase := tcase
+		t.Run(tcase.name, func(t *testing.T) {
+			require.Equal(t, tcase.match, tcase.rule.Match(attributes))
+		})
+	}
+}
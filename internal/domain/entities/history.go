@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Granularity is the size of statistics buckets.
+type Granularity string
+
+const (
+	GranularityHour Granularity = "hour"
+	GranularityDay  Granularity = "day"
+)
+
+// Duration returns the bucket size, ok is false for unknown granularity.
+func (g Granularity) Duration() (d time.Duration, ok bool) {
+	switch g {
+	case GranularityHour:
+		return time.Hour, true
+	case GranularityDay:
+		return time.Hour * 24, true
+	default:
+		return 0, false
+	}
+}
+
+// StatBucket is the banner actions of the user group in the slot during the bucket started at Start.
+type StatBucket struct {
+	Start            time.Time
+	SlotID           uint
+	BannerID         uint
+	GroupDescription string
+	Action
+}
+
+type bucketKey struct {
+	start            time.Time
+	slotID           uint
+	bannerID         uint
+	groupDescription string
+}
+
+// RollUpBuckets sums buckets into buckets of the bucket size, buckets are sorted by start time.
+func RollUpBuckets(buckets []StatBucket, size time.Duration) []StatBucket {
+	sums := make(map[bucketKey]*StatBucket)
+	rolled := make([]StatBucket, 0, len(buckets))
+	keys := make([]bucketKey, 0, len(buckets))
+	for _, bucket := range buckets {
+		key := bucketKey{
+			start:            bucket.Start.UTC().Truncate(size),
+			slotID:           bucket.SlotID,
+			bannerID:         bucket.BannerID,
+			groupDescription: bucket.GroupDescription,
+		}
+		sum, ok := sums[key]
+		if !ok {
+			sum = &StatBucket{
+				Start:            key.start,
+				SlotID:           key.slotID,
+				BannerID:         key.bannerID,
+				GroupDescription: key.groupDescription,
+			}
+			sums[key] = sum
+			keys = append(keys, key)
+		}
+		sum.Clicks += bucket.Clicks
+		sum.Shows += bucket.Shows
+		sum.Views += bucket.Views
+	}
+	for _, key := range keys {
+		rolled = append(rolled, *sums[key])
+	}
+	sort.SliceStable(rolled, func(i, j int) bool {
+		return rolled[i].Start.Before(rolled[j].Start)
+	})
+	return rolled
+}
+
+type HistoryRepository interface {
+	// GetBuckets returns hourly buckets of the page in [from, to), zero slot or banner id means any.
+	GetBuckets(ctx context.Context, pageURL string, slotInnerID, bannerInnerID uint, from, to time.Time) (buckets []StatBucket, err error)
+	// DeleteBucketsBefore deletes buckets older than the time.
+	DeleteBucketsBefore(ctx context.Context, t time.Time) error
+}
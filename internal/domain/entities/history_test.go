@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollUpBuckets(t *testing.T) {
+	day := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	buckets := []StatBucket{
+		{Start: day.Add(time.Hour * 25), SlotID: 1, BannerID: 1, GroupDescription: "old man", Action: Action{Clicks: 1, Shows: 3}},
+		{Start: day.Add(time.Hour), SlotID: 1, BannerID: 1, GroupDescription: "old man", Action: Action{Clicks: 1, Shows: 2}},
+		{Start: day.Add(time.Hour * 2), SlotID: 1, BannerID: 1, GroupDescription: "old man", Action: Action{Shows: 5}},
+		{Start: day.Add(time.Hour * 2), SlotID: 1, BannerID: 2, GroupDescription: "old man", Action: Action{Shows: 1}},
+	}
+
+	t.Run("hour", func(t *testing.T) {
+		require.Len(t, RollUpBuckets(buckets, time.Hour), 4)
+	})
+
+	t.Run("day", func(t *testing.T) {
+		rolled := RollUpBuckets(buckets, time.Hour*24)
+		require.Equal(t, []StatBucket{
+			{Start: day, SlotID: 1, BannerID: 1, GroupDescription: "old man", Action: Action{Clicks: 1, Shows: 7}},
+			{Start: day, SlotID: 1, BannerID: 2, GroupDescription: "old man", Action: Action{Shows: 1}},
+			{Start: day.Add(time.Hour * 24), SlotID: 1, BannerID: 1, GroupDescription: "old man", Action: Action{Clicks: 1, Shows: 3}},
+		}, rolled)
+	})
+}
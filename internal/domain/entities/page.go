@@ -1,9 +1,58 @@
 package entities
 
+import "context"
+
 type Page struct {
 	URL string `gorm:"UNIQUE; NOT NULL"`
+	// Algorithm is the name of the rotation algorithm for the page, empty means the default algorithm.
+	Algorithm string
+}
+
+// PageSettings are page-level rotation settings.
+type PageSettings struct {
+	Algorithm string
+	// AllowedCategories restricts banners shown on the page to these categories, empty means any banner.
+	AllowedCategories []string
+}
+
+// Allows reports whether the banner with the tags can be shown on the page.
+func (s PageSettings) Allows(tags Tags) bool {
+	if len(s.AllowedCategories) == 0 {
+		return true
+	}
+	return intersects(s.AllowedCategories, tags.Categories)
 }
 
 type PageRepository interface {
-	GetPages() (pages []Page, err error)
+	GetPages(ctx context.Context) (pages []Page, err error)
+	AddPage(ctx context.Context, pageURL string, settings PageSettings) error
+	RenamePage(ctx context.Context, pageURL, newPageURL string) error
+	SetPageSettings(ctx context.Context, pageURL string, settings PageSettings) error
+	// GetPageSettings returns settings of pages by page url.
+	GetPageSettings(ctx context.Context) (settings map[string]PageSettings, err error)
+	// DeletePage deletes the page with all its slots and their events.
+	DeletePage(ctx context.Context, pageURL string) error
+}
+
+// PageTree is the page with its slots, their banners and banner actions by user group.
+type PageTree struct {
+	Page
+	Slots []SlotTree
+}
+
+type SlotTree struct {
+	Slot
+	Banners []BannerTree
+}
+
+type BannerTree struct {
+	Banner
+	// Actions has all user groups, groups without events have zero actions.
+	Actions map[Group]Action
+}
+
+// PageTreeRepository loads page trees at once instead of querying each slot and banner.
+type PageTreeRepository interface {
+	// GetPageTrees returns the tree of the page, empty page url means trees of all pages.
+	GetPageTrees(ctx context.Context, pageURL string) (pages []PageTree, err error)
 }
@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageSettings_Allows(t *testing.T) {
+	settings := PageSettings{AllowedCategories: []string{"sport", "news"}}
+	require.True(t, settings.Allows(Tags{Categories: []string{"sport"}}))
+	require.False(t, settings.Allows(Tags{Categories: []string{"beer"}}))
+	require.False(t, settings.Allows(Tags{}))
+	require.True(t, PageSettings{}.Allows(Tags{}))
+}
@@ -0,0 +1,63 @@
+package entities
+
+import "strings"
+
+const (
+	RuleEqual    = "eq"
+	RuleNotEqual = "neq"
+	RuleIn       = "in"
+	RulePrefix   = "prefix"
+)
+
+// Rule matches a request attribute, values of the "in" operator are comma separated.
+type Rule struct {
+	Attribute string `gorm:"NOT NULL"`
+	Operator  string `gorm:"NOT NULL"`
+	Value     string
+}
+
+func (r Rule) Match(attributes map[string]string) bool {
+	value, ok := attributes[r.Attribute]
+	switch r.Operator {
+	case RuleEqual:
+		return ok && value == r.Value
+	case RuleNotEqual:
+		return value != r.Value
+	case RuleIn:
+		for _, v := range strings.Split(r.Value, ",") {
+			if ok && value == strings.TrimSpace(v) {
+				return true
+			}
+		}
+		return false
+	case RulePrefix:
+		return ok && strings.HasPrefix(value, r.Value)
+	default:
+		return false
+	}
+}
+
+// ValidateRules checks that all rules have an attribute and a known operator.
+func ValidateRules(rules []Rule) error {
+	for _, rule := range rules {
+		if rule.Attribute == "" {
+			return ErrInvalidRule(rule.Attribute, rule.Operator)
+		}
+		switch rule.Operator {
+		case RuleEqual, RuleNotEqual, RuleIn, RulePrefix:
+		default:
+			return ErrInvalidRule(rule.Attribute, rule.Operator)
+		}
+	}
+	return nil
+}
+
+// MatchRules reports whether the attributes match all rules.
+func MatchRules(rules []Rule, attributes map[string]string) bool {
+	for _, rule := range rules {
+		if !rule.Match(attributes) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package entities
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultConfidence is the confidence level of CTR intervals.
+	DefaultConfidence = 0.95
+	// probabilityGridSize is the number of points used to integrate CTR posteriors.
+	probabilityGridSize = 2000
+)
+
+// BannerSignificance is the CTR confidence interval and the probability to have the best CTR
+// of the banner among banners of the slot for the user group.
+type BannerSignificance struct {
+	SlotID           uint
+	GroupDescription string
+	BannerID         uint
+	Tries            uint
+	Clicks           uint
+	CTR              float64
+	CTRLow           float64
+	CTRHigh          float64
+	// ProbabilityToBeBest is the posterior probability that the banner has the greatest CTR.
+	ProbabilityToBeBest float64
+}
+
+// Significance returns significance of banners competing in the slot for the user group sorted by banner id.
+func Significance(slotID uint, groupDescription string, tries, clicks map[uint]uint, confidence float64) ([]BannerSignificance, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return nil, ErrInvalidConfidence(confidence)
+	}
+	z := ConfidenceZ(confidence)
+	probabilities := ProbabilityToBeBest(tries, clicks)
+	rows := make([]BannerSignificance, 0, len(tries))
+	for bannerID, n := range tries {
+		row := BannerSignificance{
+			SlotID:              slotID,
+			GroupDescription:    groupDescription,
+			BannerID:            bannerID,
+			Tries:               n,
+			Clicks:              clicks[bannerID],
+			ProbabilityToBeBest: probabilities[bannerID],
+		}
+		if n > 0 {
+			row.CTR = float64(row.Clicks) / float64(n)
+		}
+		row.CTRLow, row.CTRHigh = WilsonInterval(row.Clicks, n, z)
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].BannerID < rows[j].BannerID
+	})
+	return rows, nil
+}
+
+// ConfidenceZ returns the standard normal quantile of the two-sided confidence level in (0, 1).
+func ConfidenceZ(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
+
+// WilsonInterval returns the Wilson score interval of CTR for the normal quantile z.
+func WilsonInterval(clicks, tries uint, z float64) (low, high float64) {
+	if tries == 0 {
+		return 0, 1
+	}
+	n := float64(tries)
+	p := math.Min(float64(clicks)/n, 1)
+	z2 := z * z
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := z / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// ProbabilityToBeBest returns for each banner the probability that its CTR is the greatest,
+// CTR posteriors are Beta(clicks+1, tries-clicks+1).
+func ProbabilityToBeBest(tries, clicks map[uint]uint) map[uint]float64 {
+	probabilities := make(map[uint]float64, len(tries))
+	if len(tries) == 0 {
+		return probabilities
+	}
+	type posterior struct {
+		a, b     float64
+		mean, sd float64
+		pdf      []float64
+		cdf      []float64
+	}
+	posteriors := make(map[uint]*posterior, len(tries))
+	// integrate over the range where posteriors have mass.
+	lo, hi := 1.0, 0.0
+	for id, n := range tries {
+		c := clicks[id]
+		if c > n {
+			c = n
+		}
+		p := &posterior{a: float64(c) + 1, b: float64(n-c) + 1}
+		p.mean = p.a / (p.a + p.b)
+		p.sd = math.Sqrt(p.a * p.b / ((p.a + p.b) * (p.a + p.b) * (p.a + p.b + 1)))
+		posteriors[id] = p
+		lo = math.Min(lo, p.mean-8*p.sd)
+		hi = math.Max(hi, p.mean+8*p.sd)
+	}
+	lo, hi = math.Max(lo, 0), math.Min(hi, 1)
+	step := (hi - lo) / probabilityGridSize
+	for _, p := range posteriors {
+		p.pdf = make([]float64, probabilityGridSize)
+		p.cdf = make([]float64, probabilityGridSize)
+		lbeta := lgamma(p.a) + lgamma(p.b) - lgamma(p.a+p.b)
+		sum := 0.0
+		for i := range p.pdf {
+			if p.sd < step {
+				// the density at cell middles misses the mass of the posterior narrower than the cell,
+				// the posterior of many tries is close to normal.
+				p.pdf[i] = normalCDF(lo+float64(i+1)*step, p.mean, p.sd) - normalCDF(lo+float64(i)*step, p.mean, p.sd)
+			} else {
+				x := lo + (float64(i)+0.5)*step
+				p.pdf[i] = math.Exp((p.a-1)*math.Log(x)+(p.b-1)*math.Log1p(-x)-lbeta) * step
+			}
+			sum += p.pdf[i]
+			p.cdf[i] = sum
+		}
+		if sum == 0 {
+			continue
+		}
+		// normalize mass lost out of range.
+		for i := range p.pdf {
+			p.pdf[i] /= sum
+			p.cdf[i] /= sum
+		}
+	}
+	total := 0.0
+	for id, p := range posteriors {
+		prob := 0.0
+		for i, mass := range p.pdf {
+			others := 1.0
+			for otherID, other := range posteriors {
+				if otherID != id {
+					// others are less than x in the middle of the cell.
+					others *= other.cdf[i] - other.pdf[i]/2
+				}
+			}
+			prob += mass * others
+		}
+		probabilities[id] = prob
+		total += prob
+	}
+	if total > 0 {
+		for id := range probabilities {
+			probabilities[id] /= total
+		}
+	}
+	return probabilities
+}
+
+func normalCDF(x, mean, sd float64) float64 {
+	return 0.5 * (1 + math.Erf((x-mean)/(sd*math.Sqrt2)))
+}
+
+func lgamma(x float64) float64 {
+	v, _ := math.Lgamma(x)
+	return v
+}
@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	low, high := WilsonInterval(10, 100, ConfidenceZ(0.95))
+	require.InDelta(t, 0.0552, low, 0.0001)
+	require.InDelta(t, 0.1744, high, 0.0001)
+
+	low, high = WilsonInterval(0, 0, ConfidenceZ(0.95))
+	require.Equal(t, 0.0, low)
+	require.Equal(t, 1.0, high)
+}
+
+func TestProbabilityToBeBest(t *testing.T) {
+	t.Run("equal banners", func(t *testing.T) {
+		probs := ProbabilityToBeBest(map[uint]uint{1: 100, 2: 100}, map[uint]uint{1: 10, 2: 10})
+		require.InDelta(t, 0.5, probs[1], 0.01)
+		require.InDelta(t, 0.5, probs[2], 0.01)
+	})
+
+	t.Run("clear winner", func(t *testing.T) {
+		probs := ProbabilityToBeBest(map[uint]uint{1: 1000, 2: 1000, 3: 1000}, map[uint]uint{1: 50, 2: 100, 3: 50})
+		require.Greater(t, probs[2], 0.99)
+		require.InDelta(t, 1.0, probs[1]+probs[2]+probs[3], 0.0001)
+	})
+
+	t.Run("posterior narrower than grid cell", func(t *testing.T) {
+		probs := ProbabilityToBeBest(map[uint]uint{1: 1e9, 2: 10}, map[uint]uint{1: 1e7, 2: 1})
+		// P(Beta(2, 10) < 0.01) = 0.0052.
+		require.InDelta(t, 0.0052, probs[1], 0.001)
+		require.InDelta(t, 0.9948, probs[2], 0.001)
+
+		probs = ProbabilityToBeBest(map[uint]uint{1: 1e9, 2: 1e9}, map[uint]uint{1: 1e7, 2: 1e7})
+		require.InDelta(t, 0.5, probs[1], 0.01)
+		require.InDelta(t, 0.5, probs[2], 0.01)
+	})
+
+	t.Run("no data", func(t *testing.T) {
+		probs := ProbabilityToBeBest(map[uint]uint{1: 0, 2: 0}, nil)
+		require.InDelta(t, 0.5, probs[1], 0.01)
+	})
+}
+
+func TestSignificance(t *testing.T) {
+	rows, err := Significance(1, "kids", map[uint]uint{2: 100, 1: 0}, map[uint]uint{2: 10}, DefaultConfidence)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, uint(1), rows[0].BannerID)
+	require.Equal(t, 0.0, rows[0].CTR)
+	require.Equal(t, 0.1, rows[1].CTR)
+	require.InDelta(t, 0.0552, rows[1].CTRLow, 0.0001)
+	require.InDelta(t, 1.0, rows[0].ProbabilityToBeBest+rows[1].ProbabilityToBeBest, 0.0001)
+
+	_, err = Significance(1, "kids", nil, nil, 1)
+	require.Error(t, err)
+}
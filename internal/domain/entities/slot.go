@@ -1,13 +1,42 @@
 package entities
 
+import "context"
+
 type Slot struct {
 	InnerID     uint `gorm:"UNIQUE_INDEX:innerid_pageid; NOT NULL"`
 	Description string
+	// ViewableTry makes the bandit count viewable impressions instead of served impressions as tries.
+	ViewableTry bool `gorm:"NOT NULL; DEFAULT:false"`
+	// Width and Height are the declared slot dimensions, zero value means any size.
+	Width  uint
+	Height uint
+}
+
+// Tries returns the impressions counter which is used as the bandit's try for the slot.
+func (s Slot) Tries(action Action) uint {
+	if s.ViewableTry {
+		return action.Views
+	}
+	return action.Shows
+}
+
+// Fits reports whether the creative fits the slot's declared dimensions.
+func (s Slot) Fits(creative Creative) bool {
+	if s.Width != 0 && creative.Width > s.Width {
+		return false
+	}
+	if s.Height != 0 && creative.Height > s.Height {
+		return false
+	}
+	return true
 }
 
 type SlotRepository interface {
-	AddSlot(pageURL string, slotInnerID uint, slotDescription string) error
-	DeleteSlot(pageURL string, slotInnerID uint) error
-	DeleteAllSlots(pageURL string) error
-	GetSlotsByPageURL(pageURL string) (slots []Slot, err error)
+	AddSlot(ctx context.Context, pageURL string, slotInnerID uint, slotDescription string, viewableTry bool, width, height uint) error
+	DeleteSlot(ctx context.Context, pageURL string, slotInnerID uint) error
+	DeleteAllSlots(ctx context.Context, pageURL string) error
+	GetSlotsByPageURL(ctx context.Context, pageURL string) (slots []Slot, err error)
+	// UpdateSlot changes slot metadata, banners and their events are kept.
+	UpdateSlot(ctx context.Context, pageURL string, slotInnerID uint, slotDescription string, viewableTry bool, width, height uint) error
+	GetSlot(ctx context.Context, pageURL string, slotInnerID uint) (slot *Slot, err error)
 }
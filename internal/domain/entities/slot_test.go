@@ -0,0 +1,13 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlot_Tries(t *testing.T) {
+	action := Action{Clicks: 1, Shows: 10, Views: 4}
+	require.Equal(t, uint(10), Slot{}.Tries(action))
+	require.Equal(t, uint(4), Slot{ViewableTry: true}.Tries(action))
+}
@@ -0,0 +1,100 @@
+package entities
+
+import (
+	"sort"
+	"time"
+)
+
+// StatDimension is the dimension which statistics can be grouped by.
+type StatDimension string
+
+const (
+	StatBySlot   StatDimension = "slot"
+	StatByBanner StatDimension = "banner"
+	StatByGroup  StatDimension = "group"
+)
+
+// StatFilter restricts statistics, zero fields mean any, zero time range means lifetime statistics.
+type StatFilter struct {
+	SlotID           uint
+	BannerID         uint
+	GroupDescription string
+	From, To         time.Time
+}
+
+// StatRow is the aggregated statistics, dimensions which are not grouped by have zero values.
+type StatRow struct {
+	SlotID           uint
+	BannerID         uint
+	GroupDescription string
+	Action
+	CTR float64
+}
+
+// CTR returns click-through rate of the shows.
+func (a Action) CTR() float64 {
+	if a.Shows == 0 {
+		return 0
+	}
+	return float64(a.Clicks) / float64(a.Shows)
+}
+
+// AggregateStats sums buckets matching the filter grouped by the dimensions, rows are sorted by slot, banner and group.
+func AggregateStats(buckets []StatBucket, filter StatFilter, groupBy []StatDimension) ([]StatRow, error) {
+	by := make(map[StatDimension]bool, len(groupBy))
+	for _, dimension := range groupBy {
+		switch dimension {
+		case StatBySlot, StatByBanner, StatByGroup:
+			by[dimension] = true
+		default:
+			return nil, ErrInvalidStatDimension(string(dimension))
+		}
+	}
+	rows := make([]StatRow, 0)
+	index := make(map[StatRow]int)
+	for _, bucket := range buckets {
+		if (filter.SlotID != 0 && bucket.SlotID != filter.SlotID) ||
+			(filter.BannerID != 0 && bucket.BannerID != filter.BannerID) ||
+			(filter.GroupDescription != "" && bucket.GroupDescription != filter.GroupDescription) {
+			continue
+		}
+		var key StatRow
+		if by[StatBySlot] {
+			key.SlotID = bucket.SlotID
+		}
+		if by[StatByBanner] {
+			key.BannerID = bucket.BannerID
+		}
+		if by[StatByGroup] {
+			key.GroupDescription = bucket.GroupDescription
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(rows)
+			index[key] = i
+			rows = append(rows, key)
+		}
+		rows[i].Clicks += bucket.Clicks
+		rows[i].Shows += bucket.Shows
+		rows[i].Views += bucket.Views
+	}
+	for i := range rows {
+		rows[i].CTR = rows[i].Action.CTR()
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].SlotID != rows[j].SlotID {
+			return rows[i].SlotID < rows[j].SlotID
+		}
+		if rows[i].BannerID != rows[j].BannerID {
+			return rows[i].BannerID < rows[j].BannerID
+		}
+		return rows[i].GroupDescription < rows[j].GroupDescription
+	})
+	return rows, nil
+}
+
+// PageStatRow is the aggregated statistics of the page.
+type PageStatRow struct {
+	PageURL string
+	StatRow
+}
@@ -0,0 +1,41 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateStats(t *testing.T) {
+	buckets := []StatBucket{
+		{SlotID: 1, BannerID: 1, GroupDescription: "old man", Action: Action{Clicks: 1, Shows: 4}},
+		{SlotID: 1, BannerID: 2, GroupDescription: "old man", Action: Action{Clicks: 2, Shows: 4}},
+		{SlotID: 2, BannerID: 1, GroupDescription: "young man", Action: Action{Clicks: 1, Shows: 2}},
+	}
+
+	t.Run("total", func(t *testing.T) {
+		rows, err := AggregateStats(buckets, StatFilter{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []StatRow{{Action: Action{Clicks: 4, Shows: 10}, CTR: 0.4}}, rows)
+	})
+
+	t.Run("by banner", func(t *testing.T) {
+		rows, err := AggregateStats(buckets, StatFilter{}, []StatDimension{StatByBanner})
+		require.NoError(t, err)
+		require.Equal(t, []StatRow{
+			{BannerID: 1, Action: Action{Clicks: 2, Shows: 6}, CTR: 2.0 / 6},
+			{BannerID: 2, Action: Action{Clicks: 2, Shows: 4}, CTR: 0.5},
+		}, rows)
+	})
+
+	t.Run("filtered by group", func(t *testing.T) {
+		rows, err := AggregateStats(buckets, StatFilter{GroupDescription: "young man"}, []StatDimension{StatBySlot, StatByGroup})
+		require.NoError(t, err)
+		require.Equal(t, []StatRow{{SlotID: 2, GroupDescription: "young man", Action: Action{Clicks: 1, Shows: 2}, CTR: 0.5}}, rows)
+	})
+
+	t.Run("invalid dimension", func(t *testing.T) {
+		_, err := AggregateStats(buckets, StatFilter{}, []StatDimension{"page"})
+		require.Error(t, err)
+	})
+}
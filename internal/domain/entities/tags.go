@@ -0,0 +1,33 @@
+package entities
+
+import "context"
+
+// Tags are used for competitive exclusion of banners on the same page view.
+type Tags struct {
+	// Categories are the banner's own categories or brands.
+	Categories []string
+	// Competitors are categories which can't be shown together with the banner.
+	Competitors []string
+}
+
+// Conflicts reports whether banners with the tags compete with each other.
+func (t Tags) Conflicts(other Tags) bool {
+	return intersects(t.Competitors, other.Categories) || intersects(other.Competitors, t.Categories)
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type TagsRepository interface {
+	SetBannerTags(ctx context.Context, bannerInnerID uint, tags Tags) error
+	// GetTags returns tags of banners by banner inner id.
+	GetTags(ctx context.Context) (tags map[uint]Tags, err error)
+}
@@ -0,0 +1,62 @@
+package entities
+
+import "context"
+
+// Targeting restricts users which a banner is shown to, zero values don't restrict anything.
+type Targeting struct {
+	IncludeGroups []string
+	ExcludeGroups []string
+	MinAge        uint
+	MaxAge        uint
+	Sex           string
+}
+
+// Allow reports whether the banner can be shown to the user of the group.
+func (t Targeting) Allow(groupDescription string, userAge uint, userSex string) bool {
+	if t.Sex != "" && t.Sex != userSex {
+		return false
+	}
+	if userAge < t.MinAge || (t.MaxAge != 0 && userAge > t.MaxAge) {
+		return false
+	}
+	for _, group := range t.ExcludeGroups {
+		if group == groupDescription {
+			return false
+		}
+	}
+	if len(t.IncludeGroups) == 0 {
+		return true
+	}
+	for _, group := range t.IncludeGroups {
+		if group == groupDescription {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTargeting checks the age range and that targeted groups exist.
+func ValidateTargeting(targeting Targeting, groups []Group) error {
+	if targeting.MaxAge != 0 && targeting.MinAge > targeting.MaxAge {
+		return ErrTargetingAgeRange(targeting.MinAge, targeting.MaxAge)
+	}
+	exist := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		exist[group.Description] = true
+	}
+	for _, groupDescriptions := range [][]string{targeting.IncludeGroups, targeting.ExcludeGroups} {
+		for _, groupDescription := range groupDescriptions {
+			if !exist[groupDescription] {
+				return ErrGroupNotFound(groupDescription)
+			}
+		}
+	}
+	return nil
+}
+
+type TargetingRepository interface {
+	SetBannerTargeting(ctx context.Context, bannerInnerID uint, targeting Targeting) error
+	DeleteBannerTargeting(ctx context.Context, bannerInnerID uint) error
+	// GetTargetings returns targeting of banners by banner inner id.
+	GetTargetings(ctx context.Context) (targetings map[uint]Targeting, err error)
+}
@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargeting_Allow(t *testing.T) {
+	tcases := []struct {
+		name      string
+		targeting Targeting
+		group     string
+		age       uint
+		sex       string
+		allow     bool
+	}{
+		{name: "no restrictions", targeting: Targeting{}, group: "young man", age: 16, sex: "man", allow: true},
+		{name: "under age", targeting: Targeting{MinAge: 18}, group: "young man", age: 16, sex: "man", allow: false},
+		{name: "over age", targeting: Targeting{MinAge: 18, MaxAge: 40}, group: "old man", age: 61, sex: "man", allow: false},
+		{name: "other sex", targeting: Targeting{Sex: "women"}, group: "young man", age: 16, sex: "man", allow: false},
+		{name: "excluded", targeting: Targeting{ExcludeGroups: []string{"young man"}}, group: "young man", age: 16, sex: "man", allow: false},
+		{name: "included", targeting: Targeting{IncludeGroups: []string{"old man"}}, group: "old man", age: 61, sex: "man", allow: true},
+		{name: "not included", targeting: Targeting{IncludeGroups: []string{"old man"}}, group: "young man", age: 16, sex: "man", allow: false},
+	}
+	for _, tcase := range tcases {
+		tcase := tcase
+		t.Run(tcase.name, func(t *testing.T) {
+			require.Equal(t, tcase.allow, tcase.targeting.Allow(tcase.group, tcase.age, tcase.sex))
+		})
+	}
+}
+
+func TestTags_Conflicts(t *testing.T) {
+	cola := Tags{Categories: []string{"cola"}, Competitors: []string{"pepsi"}}
+	pepsi := Tags{Categories: []string{"pepsi"}}
+	beer := Tags{Categories: []string{"beer"}}
+	require.True(t, cola.Conflicts(pepsi))
+	require.True(t, pepsi.Conflicts(cola))
+	require.False(t, cola.Conflicts(beer))
+	require.False(t, Tags{}.Conflicts(cola))
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/shipa988/banner_rotator/cmd/rotator/api"
 	"github.com/shipa988/banner_rotator/internal/data/logger/zaplogger"
 	"github.com/shipa988/banner_rotator/internal/data/repository"
+	"github.com/shipa988/banner_rotator/internal/domain/entities"
 )
 
 const (
@@ -93,7 +94,7 @@ func (s *Suite) TestIntegration_AddSlot() {
 				SlotDescription: slotDescription,
 			},
 			postCondition: func() {
-				slots, err := s.repo.GetSlotsByPageURL(pageURL)
+				slots, err := s.repo.GetSlotsByPageURL(context.Background(), pageURL)
 				require.Nil(s.T(), err)
 				contains := false
 				for _, slot := range slots {
@@ -110,7 +111,7 @@ func (s *Suite) TestIntegration_AddSlot() {
 			name:    "duplicate",
 			headers: validMetadata,
 			preCondition: func() {
-				err := s.repo.AddSlot(pageURL, slotID, slotDescription)
+				err := s.repo.AddSlot(context.Background(), pageURL, slotID, slotDescription, false, 0, 0)
 				require.Nil(s.T(), err)
 			},
 			request: &grpcservice.RegisterSlotRequest{
@@ -118,7 +119,7 @@ func (s *Suite) TestIntegration_AddSlot() {
 				SlotDescription: slotDescription,
 			},
 			postCondition: func() {
-				slots, _ := s.repo.GetSlotsByPageURL(pageURL)
+				slots, _ := s.repo.GetSlotsByPageURL(context.Background(), pageURL)
 				require.Equal(s.T(), 1, len(slots))
 				s.AfterTest("", "")
 			},
@@ -144,7 +145,7 @@ func (s *Suite) TestIntegration_AddSlot() {
 				SlotDescription: slotDescription,
 			},
 			postCondition: func() {
-				slots, _ := s.repo.GetSlotsByPageURL(pageURL)
+				slots, _ := s.repo.GetSlotsByPageURL(context.Background(), pageURL)
 				require.Equal(s.T(), 0, len(slots))
 				s.AfterTest("", "")
 			},
@@ -186,14 +187,14 @@ func (s *Suite) TestIntegration_DeleteSlot() {
 			name:    "good",
 			headers: validMetadata,
 			preCondition: func() {
-				err := s.repo.AddSlot(pageURL, slotID, slotDescription)
+				err := s.repo.AddSlot(context.Background(), pageURL, slotID, slotDescription, false, 0, 0)
 				require.Nil(s.T(), err)
 			},
 			request: &grpcservice.DeleteSlotRequest{
 				SlotId: slotID,
 			},
 			postCondition: func() {
-				slots, _ := s.repo.GetSlotsByPageURL(pageURL)
+				slots, _ := s.repo.GetSlotsByPageURL(context.Background(), pageURL)
 				require.Equal(s.T(), 0, len(slots))
 				s.AfterTest("", "")
 			},
@@ -225,14 +226,14 @@ func (s *Suite) TestIntegration_DeleteSlot() {
 			name:    "not found",
 			headers: validMetadata,
 			preCondition: func() {
-				err := s.repo.AddSlot(pageURL, slotID, slotDescription)
+				err := s.repo.AddSlot(context.Background(), pageURL, slotID, slotDescription, false, 0, 0)
 				require.Nil(s.T(), err)
 			},
 			request: &grpcservice.DeleteSlotRequest{
 				SlotId: 2,
 			},
 			postCondition: func() {
-				slots, _ := s.repo.GetSlotsByPageURL(pageURL)
+				slots, _ := s.repo.GetSlotsByPageURL(context.Background(), pageURL)
 				require.Equal(s.T(), 1, len(slots))
 				s.AfterTest("", "")
 			},
@@ -281,7 +282,7 @@ func (s *Suite) TestIntegration_AddBanner() {
 				BannerDescription: bannerDescription,
 			},
 			postCondition: func() {
-				banners, err := s.repo.GetBannersBySlotID(pageURL, slotID)
+				banners, err := s.repo.GetBannersBySlotID(context.Background(), pageURL, slotID)
 				require.NotNil(s.T(), err)
 				require.Equal(s.T(), 0, len(banners))
 				s.AfterTest("", "")
@@ -292,7 +293,7 @@ func (s *Suite) TestIntegration_AddBanner() {
 			name:    "good",
 			headers: validMetadata,
 			preCondition: func() {
-				err := s.repo.AddSlot(pageURL, slotID, slotDescription)
+				err := s.repo.AddSlot(context.Background(), pageURL, slotID, slotDescription, false, 0, 0)
 				require.Nil(s.T(), err)
 			},
 			request: &grpcservice.RegisterBannerRequest{
@@ -301,7 +302,7 @@ func (s *Suite) TestIntegration_AddBanner() {
 				BannerDescription: bannerDescription,
 			},
 			postCondition: func() {
-				banners, err := s.repo.GetBannersBySlotID(pageURL, slotID)
+				banners, err := s.repo.GetBannersBySlotID(context.Background(), pageURL, slotID)
 				require.Nil(s.T(), err)
 				contains := false
 				for _, banner := range banners {
@@ -360,9 +361,9 @@ func (s *Suite) TestIntegration_DeleteBanner() {
 			name:    "good",
 			headers: validMetadata,
 			preCondition: func() {
-				err := s.repo.AddSlot(pageURL, slotID, slotDescription)
+				err := s.repo.AddSlot(context.Background(), pageURL, slotID, slotDescription, false, 0, 0)
 				require.Nil(s.T(), err)
-				err = s.repo.AddBannerToSlot(pageURL, slotID, bannerID, bannerDescription)
+				err = s.repo.AddBannerToSlot(context.Background(), pageURL, slotID, bannerID, bannerDescription, entities.Creative{})
 				require.Nil(s.T(), err)
 			},
 			request: &grpcservice.DeleteBannerRequest{
@@ -370,7 +371,7 @@ func (s *Suite) TestIntegration_DeleteBanner() {
 				BannerId: bannerID,
 			},
 			postCondition: func() {
-				banners, _ := s.repo.GetBannersBySlotID(pageURL, slotID)
+				banners, _ := s.repo.GetBannersBySlotID(context.Background(), pageURL, slotID)
 				require.Equal(s.T(), 0, len(banners))
 				s.AfterTest("", "")
 			},
@@ -391,9 +392,9 @@ func (s *Suite) TestIntegration_DeleteBanner() {
 			name:    "bad zero value",
 			headers: validMetadata,
 			preCondition: func() {
-				err := s.repo.AddSlot(pageURL, slotID, slotDescription)
+				err := s.repo.AddSlot(context.Background(), pageURL, slotID, slotDescription, false, 0, 0)
 				require.Nil(s.T(), err)
-				err = s.repo.AddBannerToSlot(pageURL, slotID, bannerID, bannerDescription)
+				err = s.repo.AddBannerToSlot(context.Background(), pageURL, slotID, bannerID, bannerDescription, entities.Creative{})
 				require.Nil(s.T(), err)
 			},
 			request: &grpcservice.DeleteBannerRequest{
@@ -401,7 +402,7 @@ func (s *Suite) TestIntegration_DeleteBanner() {
 				BannerId: 0,
 			},
 			postCondition: func() {
-				banners, _ := s.repo.GetBannersBySlotID(pageURL, slotID)
+				banners, _ := s.repo.GetBannersBySlotID(context.Background(), pageURL, slotID)
 				require.Equal(s.T(), 1, len(banners))
 				s.AfterTest("", "")
 			},
@@ -411,16 +412,16 @@ func (s *Suite) TestIntegration_DeleteBanner() {
 			name:    "not found",
 			headers: validMetadata,
 			preCondition: func() {
-				err := s.repo.AddSlot(pageURL, slotID, slotDescription)
+				err := s.repo.AddSlot(context.Background(), pageURL, slotID, slotDescription, false, 0, 0)
 				require.Nil(s.T(), err)
-				err = s.repo.AddBannerToSlot(pageURL, slotID, bannerID, bannerDescription)
+				err = s.repo.AddBannerToSlot(context.Background(), pageURL, slotID, bannerID, bannerDescription, entities.Creative{})
 				require.Nil(s.T(), err)
 			},
 			request: &grpcservice.DeleteBannerRequest{
 				SlotId: 2,
 			},
 			postCondition: func() {
-				slots, _ := s.repo.GetSlotsByPageURL(pageURL)
+				slots, _ := s.repo.GetSlotsByPageURL(context.Background(), pageURL)
 				require.Equal(s.T(), 1, len(slots))
 				s.AfterTest("", "")
 			},
@@ -469,12 +470,12 @@ func (s *Suite) TestIntegration_ClickOnBanner() {
 				UserSex:  userSex,
 			},
 			postCondition: func() {
-				group, err := s.repo.GetGroup(userAge, userSex)
+				group, err := s.repo.GetGroup(context.Background(), userAge, userSex)
 				require.Nil(s.T(), err)
 				// 10 tries - because queue makes delay
 				clicks := uint(0)
 				for i := 0; i < 10; i++ {
-					actions, _ := s.repo.GetActions(pageURL, slotID, bannerID)
+					actions, _ := s.repo.GetActions(context.Background(), pageURL, slotID, bannerID)
 					if actions[*group].Clicks != 0 {
 						clicks = actions[*group].Clicks
 						break
@@ -490,9 +491,9 @@ func (s *Suite) TestIntegration_ClickOnBanner() {
 			name:    "good",
 			headers: validMetadata,
 			preCondition: func() {
-				err := s.repo.AddSlot(pageURL, slotID, slotDescription)
+				err := s.repo.AddSlot(context.Background(), pageURL, slotID, slotDescription, false, 0, 0)
 				require.Nil(s.T(), err)
-				err = s.repo.AddBannerToSlot(pageURL, slotID, bannerID, bannerDescription)
+				err = s.repo.AddBannerToSlot(context.Background(), pageURL, slotID, bannerID, bannerDescription, entities.Creative{})
 				require.Nil(s.T(), err)
 			},
 			request: &grpcservice.ClickRequest{
@@ -502,12 +503,12 @@ func (s *Suite) TestIntegration_ClickOnBanner() {
 				UserSex:  userSex,
 			},
 			postCondition: func() {
-				group, err := s.repo.GetGroup(userAge, userSex)
+				group, err := s.repo.GetGroup(context.Background(), userAge, userSex)
 				require.Nil(s.T(), err)
 				// 10 tries - because queue makes delay
 				clicks := uint(0)
 				for i := 0; i < 10; i++ {
-					actions, err := s.repo.GetActions(pageURL, slotID, bannerID)
+					actions, err := s.repo.GetActions(context.Background(), pageURL, slotID, bannerID)
 					require.Nil(s.T(), err)
 					if actions[*group].Clicks != 0 {
 						clicks = actions[*group].Clicks
@@ -577,12 +578,12 @@ func (s *Suite) TestIntegration_GetNextBanner() {
 				BannerId: 0,
 			},
 			postCondition: func() {
-				group, err := s.repo.GetGroup(userAge, userSex)
+				group, err := s.repo.GetGroup(context.Background(), userAge, userSex)
 				require.Nil(s.T(), err)
 				// 10 tries - because queue makes delay
 				shows := uint(0)
 				for i := 0; i < 10; i++ {
-					actions, _ := s.repo.GetActions(pageURL, slotID, bannerID)
+					actions, _ := s.repo.GetActions(context.Background(), pageURL, slotID, bannerID)
 					if actions[*group].Shows != 0 {
 						shows = actions[*group].Shows
 						break
@@ -598,9 +599,9 @@ func (s *Suite) TestIntegration_GetNextBanner() {
 			name:    "good",
 			headers: validMetadata,
 			preCondition: func() {
-				err := s.repo.AddSlot(pageURL, slotID, slotDescription)
+				err := s.repo.AddSlot(context.Background(), pageURL, slotID, slotDescription, false, 0, 0)
 				require.Nil(s.T(), err)
-				err = s.repo.AddBannerToSlot(pageURL, slotID, bannerID, bannerDescription)
+				err = s.repo.AddBannerToSlot(context.Background(), pageURL, slotID, bannerID, bannerDescription, entities.Creative{})
 				require.Nil(s.T(), err)
 			},
 			request: &grpcservice.GetNextBannerRequest{
@@ -612,12 +613,12 @@ func (s *Suite) TestIntegration_GetNextBanner() {
 				BannerId: bannerID,
 			},
 			postCondition: func() {
-				group, err := s.repo.GetGroup(userAge, userSex)
+				group, err := s.repo.GetGroup(context.Background(), userAge, userSex)
 				require.Nil(s.T(), err)
 				// 10 tries - because queue makes delay
 				shows := uint(0)
 				for i := 0; i < 10; i++ {
-					actions, err := s.repo.GetActions(pageURL, slotID, bannerID)
+					actions, err := s.repo.GetActions(context.Background(), pageURL, slotID, bannerID)
 					require.Nil(s.T(), err)
 					if actions[*group].Shows != 0 {
 						shows = actions[*group].Shows